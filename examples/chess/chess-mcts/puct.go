@@ -0,0 +1,147 @@
+package chessmcts
+
+/*
+Chess MCTS (PUCT) example
+
+Same game operations as the UCB1 example, but the selection policy is PUCT,
+which needs a prior for every move. Priors come from the Priors method
+(mcts.PriorGameOperations), called by the search right after ExpandNode.
+
+Move ordering heuristic:
+  - mating moves get a very high weight
+  - other moves are weighted by how much they restrict the opponent's mobility,
+    so checks (few legal replies) and forcing moves are searched first
+
+The weights don't have to sum up to 1, the search normalizes them.
+*/
+
+import (
+	"math"
+	"math/rand"
+
+	chess "github.com/IlikeChooros/dragontoothmg"
+	mcts "github.com/IlikeChooros/go-mcts/pkg/mcts"
+)
+
+// PuctGameOps implements mcts.GameOperations and mcts.PriorGameOperations for chess.
+type PuctGameOps struct {
+	board  *chess.Board
+	random *rand.Rand // injected by the search worker via SetRand
+}
+
+// PuctMctsType wires the generic MCTS to the chess operations, with PuctStats holding the priors.
+type PuctMctsType struct {
+	mcts.MCTS[chess.Move, *mcts.PuctStats, mcts.Result, *PuctGameOps, *mcts.PUCT[chess.Move, *mcts.PuctStats, mcts.Result, *PuctGameOps]]
+}
+
+// NewPuctMcts constructs a ready-to-search MCTS instance for chess with PUCT selection.
+func NewPuctMcts() *PuctMctsType {
+	return &PuctMctsType{
+		MCTS: *mcts.NewMTCS(
+			mcts.NewPUCT[chess.Move, *mcts.PuctStats, mcts.Result, *PuctGameOps](1.5), // selection policy (c_puct)
+			newPuctGameOps(),             // game operations (implements Expand/Priors/Rollout/etc.)
+			mcts.MultithreadTreeParallel, // threading policy
+			&mcts.PuctStats{},            // per-node statistics (visits, wins, virtual loss, prior)
+		),
+	}
+}
+
+// Search runs a (possibly multi-threaded) search and waits until done.
+func (puct *PuctMctsType) Search() {
+	puct.SearchMultiThreaded()
+	puct.Synchronize()
+}
+
+// newPuctGameOps creates a fresh operations object starting from the initial chess position.
+func newPuctGameOps() *PuctGameOps {
+	return &PuctGameOps{
+		board: chess.NewBoard(),
+	}
+}
+
+// ExpandNode adds all legal child moves under p, initializing each child node.
+func (o *PuctGameOps) ExpandNode(p *mcts.NodeBase[chess.Move, *mcts.PuctStats]) uint32 {
+	moves := o.board.GenerateLegalMoves()
+	p.Children = make([]mcts.NodeBase[chess.Move, *mcts.PuctStats], len(moves))
+
+	for i := range moves {
+		o.board.Make(moves[i])
+		isTerminal := o.board.IsTerminated(len(o.board.GenerateLegalMoves()))
+		o.board.Undo()
+
+		p.Children[i] = *mcts.NewBaseNode(p, moves[i], isTerminal, &mcts.PuctStats{})
+	}
+
+	return uint32(len(moves))
+}
+
+// Priors fills the heuristic weights of p's children (the board is set to p's position).
+func (o *PuctGameOps) Priors(p *mcts.NodeBase[chess.Move, *mcts.PuctStats], priors []float64) {
+	for i := range p.Children {
+		o.board.Make(p.Children[i].Move)
+		replies := len(o.board.GenerateLegalMoves())
+
+		switch {
+		case o.board.IsTerminated(replies) && o.board.Termination() == chess.TerminationCheckmate:
+			priors[i] = 100
+		case o.board.IsTerminated(replies):
+			priors[i] = 0.5 // draw
+		default:
+			priors[i] = 1 + 4/math.Sqrt(float64(replies))
+		}
+		o.board.Undo()
+	}
+}
+
+// Traverse applies a move to the board when descending the tree.
+func (o *PuctGameOps) Traverse(m chess.Move) {
+	o.board.Make(m)
+}
+
+// BackTraverse undoes the last move when ascending during backpropagation.
+func (o *PuctGameOps) BackTraverse() {
+	o.board.Undo()
+}
+
+// Rollout plays random moves until the game terminates, see UcbGameOps.Rollout.
+func (o *PuctGameOps) Rollout() mcts.Result {
+	var result mcts.Result = 0.5
+	moveCount := 0
+	leafIsWhite := o.board.Wtomove
+
+	moves := o.board.GenerateLegalMoves()
+	for !o.board.IsTerminated(len(moves)) {
+		moveCount++
+		o.board.Make(moves[o.random.Int()%len(moves)])
+		moves = o.board.GenerateLegalMoves()
+	}
+
+	if o.board.Termination() == chess.TerminationCheckmate {
+		if o.board.Wtomove == leafIsWhite {
+			result = 0.0
+		} else {
+			result = 1.0
+		}
+	}
+
+	for range moveCount {
+		o.board.Undo()
+	}
+
+	return result
+}
+
+// Reset allows you to clear any per-search state (none needed here).
+func (o *PuctGameOps) Reset() {}
+
+// SetRand is called once per worker to provide a thread-local RNG.
+func (o *PuctGameOps) SetRand(r *rand.Rand) {
+	o.random = r
+}
+
+// Clone returns a deep copy of the operations object for worker threads.
+func (o *PuctGameOps) Clone() *PuctGameOps {
+	return &PuctGameOps{
+		board: o.board.Clone(),
+	}
+}
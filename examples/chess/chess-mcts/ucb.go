@@ -15,6 +15,7 @@ Important integration points:
   - ExpandNode must enumerate legal moves from the current board state and append children nodes.
   - Traverse applies a move to the board; BackTraverse undoes it.
   - Rollout performs a light playout (random moves) until termination and returns a result in [0.0, 1.0].
+    Optionally, the moves are chosen with a MAST/NST policy learned during the search (see SetRolloutPolicy).
   - SetRand is invoked by the MCTS worker to provide a per-thread RNG; use it for randomized rollouts.
 
 Threading:
@@ -24,6 +25,7 @@ Threading:
 
 import (
 	"math/rand"
+	"slices"
 
 	chess "github.com/IlikeChooros/dragontoothmg"
 	mcts "github.com/IlikeChooros/go-mcts/pkg/mcts"
@@ -33,7 +35,9 @@ import (
 // It owns the board state and is cloned per worker for thread safety.
 type UcbGameOps struct {
 	board  *chess.Board
-	random *rand.Rand // injected by the search worker via SetRand
+	random *rand.Rand             // injected by the search worker via SetRand
+	mast   *mcts.MAST[chess.Move] // optional rollout policy, shared by the clones
+	path   []chess.Move           // moves from the root to the current position, then the rollout moves
 }
 
 // UcbMctsType wires the generic MCTS to the chess-specific operations and types.
@@ -88,11 +92,22 @@ func (o *UcbGameOps) ExpandNode(p *mcts.NodeBase[chess.Move, *mcts.NodeStats]) u
 // Traverse applies a move to the board when descending the tree.
 func (o *UcbGameOps) Traverse(m chess.Move) {
 	o.board.Make(m)
+	o.path = append(o.path, m)
 }
 
 // BackTraverse undoes the last move when ascending during backpropagation.
 func (o *UcbGameOps) BackTraverse() {
 	o.board.Undo()
+	// Backpropagation ascends through the root as well.
+	if len(o.path) > 0 {
+		o.path = o.path[:len(o.path)-1]
+	}
+}
+
+// SetRolloutPolicy makes the rollouts choose the moves with the MAST (or NST) policy,
+// which learns the average move results after every rollout. Nil restores the random moves.
+func (o *UcbGameOps) SetRolloutPolicy(policy *mcts.MAST[chess.Move]) {
+	o.mast = policy
 }
 
 // Rollout plays random moves until the game terminates and returns the result
@@ -102,14 +117,22 @@ func (o *UcbGameOps) Rollout() mcts.Result {
 	var result mcts.Result = 0.5
 	moveCount := 0
 	leafIsWhite := o.board.Wtomove
+	treeMoves := len(o.path)
 
 	// Generate initial moves once; subsequent iterations update it after making a move.
 	moves := o.board.GenerateLegalMoves()
 	for !o.board.IsTerminated(len(moves)) {
 		moveCount++
 
-		// Choose a random legal move using the worker-provided RNG.
-		o.board.Make(moves[o.random.Int()%len(moves)])
+		if o.mast != nil {
+			// Choose the move with the learned policy, remembering it for the update.
+			move := moves[o.mast.Choose(o.random, o.path, moves)]
+			o.path = append(o.path, move)
+			o.board.Make(move)
+		} else {
+			// Choose a random legal move using the worker-provided RNG.
+			o.board.Make(moves[o.random.Int()%len(moves)])
+		}
 		moves = o.board.GenerateLegalMoves()
 	}
 
@@ -123,6 +146,17 @@ func (o *UcbGameOps) Rollout() mcts.Result {
 	}
 	// Else stalemate or other draw, keep 0.5.
 
+	// Credit the tree and rollout moves, the policy expects the result of the player who made
+	// the first one (the leaf side to move, if the tree moves are even).
+	if o.mast != nil {
+		if treeMoves%2 == 0 {
+			o.mast.Update(o.path, result)
+		} else {
+			o.mast.Update(o.path, 1-result)
+		}
+		o.path = o.path[:treeMoves]
+	}
+
 	// Rewind the board back to the leaf state.
 	for range moveCount {
 		o.board.Undo()
@@ -144,5 +178,7 @@ func (o *UcbGameOps) SetRand(r *rand.Rand) {
 func (o *UcbGameOps) Clone() *UcbGameOps {
 	return &UcbGameOps{
 		board: o.board.Clone(),
+		mast:  o.mast,
+		path:  slices.Clone(o.path),
 	}
 }
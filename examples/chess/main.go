@@ -10,10 +10,12 @@ What it shows:
 - Constructing a chess-specific MCTS instance (UCB1 + default backprop).
 - Setting time/thread limits for the search.
 - Tuning the UCB exploration constant (c).
+- Optionally learning the rollout policy during the search (-rollout mast|nst).
 - Subscribing a listener to print UCI-like "info ... pv ..." lines and "bestmove ...".
 */
 
 import (
+	"flag"
 	"fmt"
 	"strings"
 
@@ -32,6 +34,9 @@ func MovesToString(mvs []dragontoothmg.Move) string {
 }
 
 func main() {
+	rollout := flag.String("rollout", "random", "Rollout moves: random, mast or nst")
+	flag.Parse()
+
 	// Build a chess-specific MCTS instance configured for:
 	// - UCB1 selection policy
 	// - NodeStats for per-node visits/outcomes
@@ -47,6 +52,15 @@ func main() {
 	// You can also call mcts.SetExplorationParam(c) to clamp to >= 0.
 	ucb.Strategy().SetExplorationParam(0.3)
 
+	// Rollouts pick uniformly random moves by default. MAST learns the average result of every move
+	// during the search and prefers the good ones (Gibbs sampling); NST also averages the move pairs.
+	switch *rollout {
+	case "mast":
+		ucb.Ops().SetRolloutPolicy(mcts.NewMAST[dragontoothmg.Move]())
+	case "nst":
+		ucb.Ops().SetRolloutPolicy(mcts.NewNST[dragontoothmg.Move](2))
+	}
+
 	// Attach a listener to stream live search stats.
 	// OnDepth is called periodically; we print UCI-like lines with eval, depth, CPS, PV.
 	// OnStop fires once at the end; we repeat the final line and print bestmove.
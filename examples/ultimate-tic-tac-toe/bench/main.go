@@ -4,7 +4,8 @@ package main
 
 Ultimate Tic-Tac-Toe benchmarking example
 
-Uses UCB1 and RAVE implementations to play against each other in an arena setup
+Uses UCB1 and RAVE implementations to play against each other in an arena setup,
+with -opponent the UCB1 player faces the variance-aware UCB1-Tuned or UCB-V instead
 
 
 
@@ -21,6 +22,7 @@ import (
 	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
 	rave "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/rave"
 	ucb "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/ucb"
+	variance "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/variance"
 	"github.com/IlikeChooros/go-mcts/pkg/bench"
 	"github.com/IlikeChooros/go-mcts/pkg/mcts"
 )
@@ -33,14 +35,30 @@ type utttOpsLike[S mcts.NodeStatsLike[S], R mcts.GameResult, G mcts.GameOperatio
 
 type baseMCTS[S mcts.NodeStatsLike[S], R mcts.GameResult, G utttOpsLike[S, R, G], A mcts.StrategyLike[uttt.PosType, S, R, G]] struct {
 	mcts.MCTS[uttt.PosType, S, R, G, A]
+	policy mcts.BestChildPolicy // how the played move is chosen
 }
 
 func (b *baseMCTS[S, R, G, A]) Search() uttt.PosType {
-	b.SearchMultiThreaded()
+	// After a ponderhit the search is already running
+	if !b.IsSearching() {
+		b.SearchMultiThreaded()
+	}
 	b.Synchronize()
+
+	// Self-play samples the move from the visits, for varied games
+	if child := b.BestChild(b.Root, b.policy); child != nil {
+		return child.Move
+	}
 	return b.BestMove()
 }
 
+// Root noise and move sampling in the first 'moves' plies, so that the games don't repeat
+func (b *baseMCTS[S, R, G, A]) SetSelfPlay(moves int) {
+	b.SetRootNoise(0.3, 0.25)
+	b.SetTemperature(mcts.StepTemperature(1, moves, 0))
+	b.policy = mcts.BestChildSampled
+}
+
 type ucbMCTS struct {
 	baseMCTS[*mcts.NodeStats, mcts.Result, *ucb.UtttOperations, *mcts.UCB1[uttt.PosType, *mcts.NodeStats, mcts.Result, *ucb.UtttOperations]]
 }
@@ -74,7 +92,8 @@ func (u *ucbMCTS) SetPosition(position *uttt.Position) {
 
 func (u *ucbMCTS) Clone() bench.ExtMCTS[uttt.PosType, *mcts.NodeStats, mcts.Result, *uttt.Position] {
 	return &ucbMCTS{baseMCTS: baseMCTS[*mcts.NodeStats, mcts.Result, *ucb.UtttOperations, *mcts.UCB1[uttt.PosType, *mcts.NodeStats, mcts.Result, *ucb.UtttOperations]]{
-		MCTS: *u.MCTS.Clone(),
+		MCTS:   *u.MCTS.Clone(),
+		policy: u.policy,
 	}}
 }
 
@@ -103,105 +122,206 @@ func (r *raveMCTS) SetPosition(position *uttt.Position) {
 
 func (r *raveMCTS) Clone() bench.ExtMCTS[uttt.PosType, *mcts.RaveStats, *rave.UtttGameResult, *uttt.Position] {
 	return &raveMCTS{baseMCTS: baseMCTS[*mcts.RaveStats, *rave.UtttGameResult, *rave.UtttOperations, *mcts.RAVE[uttt.PosType, *mcts.RaveStats, *rave.UtttGameResult, *rave.UtttOperations]]{
-		MCTS: *r.MCTS.Clone(),
+		MCTS:   *r.MCTS.Clone(),
+		policy: r.policy,
 	}}
 }
 
-type versusData struct {
-	bench.VersusSummaryInfo
-	P1Exp        float64 `json:"p1_exploration_param"`
-	P2Exp        float64 `json:"p2_exploration_param"`
-	BetaFunction string  `json:"beta_function"`
+// Player 2 with the variance-aware selection (UCB1-Tuned or UCB-V)
+type varianceMCTS struct {
+	baseMCTS[*mcts.VarianceStats, mcts.Result, *variance.UtttOperations, variance.Strategy]
 }
 
-func main() {
-	saveToFile := flag.String("save", "", "Save game results to the specified file")
-	flag.Parse()
+func NewVariance(strategy variance.Strategy) *varianceMCTS {
+	// Each mcts instance must have its own operations instance
+	return &varianceMCTS{
+		baseMCTS: baseMCTS[*mcts.VarianceStats, mcts.Result, *variance.UtttOperations, variance.Strategy]{
+			MCTS: *mcts.NewMTCS(
+				strategy,
+				variance.NewUtttOps(*uttt.NewPosition()),
+				mcts.MultithreadTreeParallel,
+				&mcts.VarianceStats{},
+			),
+		},
+	}
+}
 
-	const (
-		maxThreads = 1     // threads per mcts instance (total threads = maxThreads * arenaThreads)
-		totalGames = 500   // total games to play
-		maxCycles  = 50000 // cycles/iterations per mcts instance
+func (v *varianceMCTS) Reset() {
+	v.baseMCTS.Reset(v.baseMCTS.Ops().Position().IsTerminated(), &mcts.VarianceStats{})
+}
 
-		arenaThreads = 12 // threads for the arena manager
-	)
+func (v *varianceMCTS) SetPosition(position *uttt.Position) {
+	v.Ops().SetPosition(*position)
+	v.Reset()
+}
 
-	ucbmcts := NewUcb()
-	ravemcts := NewRave()
+func (v *varianceMCTS) Clone() bench.ExtMCTS[uttt.PosType, *mcts.VarianceStats, mcts.Result, *uttt.Position] {
+	return &varianceMCTS{baseMCTS: baseMCTS[*mcts.VarianceStats, mcts.Result, *variance.UtttOperations, variance.Strategy]{
+		MCTS:   *v.MCTS.Clone(),
+		policy: v.policy,
+	}}
+}
 
-	// Fine tune UCB1 exploration parameter
-	ucbmcts.Strategy().SetExplorationParam(0.4)
+type versusData struct {
+	bench.VersusSummaryInfo
+	P1Exp        float64 `json:"p1_exploration_param"`
+	P2Exp        float64 `json:"p2_exploration_param"`
+	BetaFunction string  `json:"beta_function,omitempty"`
+}
 
-	// Fine tune RAVE parameters
-	const (
-		K      = 10000
-		alpha  = 0.4
-		P1Name = "UCB1"
-		P2Name = "RAVE"
-	)
-	// const K = 30000
-	ravemcts.Strategy().SetBetaFunction(func(n, nRave int32) float64 {
-		// Using an example from: https://users.soe.ucsc.edu/~dph/mypubs/AMAFpaperWithRef.pdf
-		if n > K {
-			return 0.0
-		}
-		return float64(K-n) / K
-		// return alpha // alpha AMAF
-	})
-	ravemcts.Strategy().SetExplorationParam(0.35)
-
-	// Setup and run the arena
-	limits := mcts.DefaultLimits().SetThreads(maxThreads).SetCycles(maxCycles)
-	arena := bench.NewVersusArena(uttt.NewPosition(),
-		bench.ExtMCTS[uttt.PosType, *mcts.NodeStats, mcts.Result, *uttt.Position](ucbmcts),
-		bench.ExtMCTS[uttt.PosType, *mcts.RaveStats, *rave.UtttGameResult, *uttt.Position](ravemcts),
-	)
-	arena.Setup(limits, totalGames, arenaThreads)
+type arenaOptions struct {
+	limits  *mcts.Limits
+	games   uint
+	threads uint
+	ponder1 bool
+	ponder2 bool
+	p1Name  string
+	p2Name  string
+}
+
+// Plays the games between the players, returns the results (also of the games played before Ctrl-C or a panic)
+func playArena[S1 mcts.NodeStatsLike[S1], R1 mcts.GameResult, S2 mcts.NodeStatsLike[S2], R2 mcts.GameResult](
+	player1 bench.ExtMCTS[uttt.PosType, S1, R1, *uttt.Position],
+	player2 bench.ExtMCTS[uttt.PosType, S2, R2, *uttt.Position],
+	opts arenaOptions,
+) (results bench.VersusSummaryInfo) {
+	arena := bench.NewVersusArena(uttt.NewPosition(), player1, player2)
+	arena.Setup(opts.limits, opts.games, opts.threads)
+	arena.SetPondering(opts.ponder1, opts.ponder2)
 	ctx, cancel := context.WithCancel(context.Background())
 	arena.WithContext(ctx)
 
 	// Handle Ctrl-C
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
+	defer signal.Stop(c)
 	go func() {
 		<-c
 		cancel()
 	}()
 
-	// Ensure results are saved on panic or normal exit
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Printf("Panic: %v\n", r)
 		}
+		results = arena.Results()
+	}()
 
-		if *saveToFile != "" {
-			f, err := os.Create(*saveToFile)
-			if err != nil {
-				panic(err)
-			}
-			defer f.Close()
-			data := versusData{
-				VersusSummaryInfo: arena.Results(),
-				P1Exp:             ucbmcts.Strategy().ExplorationParam,
-				P2Exp:             ravemcts.Strategy().ExplorationParam,
-				BetaFunction:      fmt.Sprintf("K=%d k-n/k", K),
-				// BetaFunction: fmt.Sprintf("alpha=%.2f", alpha),
-			}
-			jsonData, err := json.Marshal(data)
-			if err != nil {
-				panic(err)
-			}
-			_, err = f.WriteString(string(jsonData))
-			if err != nil {
-				panic(err)
+	arena.Start(opts.p1Name, opts.p2Name, &bench.DefaultListener[uttt.PosType]{})
+	arena.Wait()
+	return arena.Results()
+}
+
+func main() {
+	saveToFile := flag.String("save", "", "Save game results to the specified file")
+	opponent := flag.String("opponent", "rave", "Player 2 selection strategy: rave, ucb1-tuned or ucbv")
+	ponder1 := flag.Bool("ponder1", false, "Player 1 (UCB1) searches on the opponent's time")
+	ponder2 := flag.Bool("ponder2", false, "Player 2 searches on the opponent's time")
+	selfPlay := flag.Int("selfplay", 0, "Sample the moves (with root noise) in the first N plies, for varied games")
+	flag.Parse()
+
+	const (
+		maxThreads = 1     // threads per mcts instance (total threads = maxThreads * arenaThreads)
+		totalGames = 500   // total games to play
+		maxCycles  = 50000 // cycles/iterations per mcts instance
+
+		arenaThreads = 12 // threads for the arena manager
+	)
+
+	// Setup the arena
+	// Smart stop ends the search once the best move is certain, freeing CPU for the other games
+	opts := arenaOptions{
+		limits:  mcts.DefaultLimits().SetThreads(maxThreads).SetCycles(maxCycles).SetSmartStop(true),
+		games:   totalGames,
+		threads: arenaThreads,
+		ponder1: *ponder1,
+		ponder2: *ponder2,
+		p1Name:  "UCB1",
+	}
+
+	// P1: UCB1, fine tune the exploration parameter
+	ucbmcts := NewUcb()
+	ucbmcts.Strategy().SetExplorationParam(0.4)
+	if *selfPlay > 0 {
+		ucbmcts.SetSelfPlay(*selfPlay)
+	}
+	data := versusData{P1Exp: ucbmcts.Strategy().ExplorationParam}
+
+	// P2: chosen by the flag
+	switch *opponent {
+	case "rave":
+		ravemcts := NewRave()
+
+		// Fine tune RAVE parameters
+		const (
+			K     = 10000
+			alpha = 0.4
+		)
+		// const K = 30000
+		ravemcts.Strategy().SetBetaFunction(func(n, nRave int32) float64 {
+			// Using an example from: https://users.soe.ucsc.edu/~dph/mypubs/AMAFpaperWithRef.pdf
+			if n > K {
+				return 0.0
 			}
+			return float64(K-n) / K
+			// return alpha // alpha AMAF
+		})
+		ravemcts.Strategy().SetExplorationParam(0.35)
+		if *selfPlay > 0 {
+			ravemcts.SetSelfPlay(*selfPlay)
+		}
 
-			fmt.Printf("Results saved to %s\n", *saveToFile)
+		opts.p2Name = "RAVE"
+		data.P2Exp = ravemcts.Strategy().ExplorationParam
+		data.BetaFunction = fmt.Sprintf("K=%d k-n/k", K)
+		// data.BetaFunction = fmt.Sprintf("alpha=%.2f", alpha)
+		data.VersusSummaryInfo = playArena(
+			bench.ExtMCTS[uttt.PosType, *mcts.NodeStats, mcts.Result, *uttt.Position](ucbmcts),
+			bench.ExtMCTS[uttt.PosType, *mcts.RaveStats, *rave.UtttGameResult, *uttt.Position](ravemcts),
+			opts,
+		)
+
+	case "ucb1-tuned", "ucbv":
+		// Variance-aware strategies, with the parameters from the papers
+		var strategy variance.Strategy
+		if *opponent == "ucbv" {
+			ucbv := variance.NewUCBV()
+			strategy, opts.p2Name, data.P2Exp = ucbv, "UCB-V", ucbv.ExplorationParam
+		} else {
+			tuned := variance.NewUCB1Tuned()
+			strategy, opts.p2Name, data.P2Exp = tuned, "UCB1-Tuned", tuned.ExplorationParam
 		}
-	}()
 
-	// P1: UCB1
-	// P2: RAVE
-	arena.Start(P1Name, P2Name, &bench.DefaultListener[uttt.PosType]{})
-	arena.Wait()
+		variancemcts := NewVariance(strategy)
+		if *selfPlay > 0 {
+			variancemcts.SetSelfPlay(*selfPlay)
+		}
+		data.VersusSummaryInfo = playArena(
+			bench.ExtMCTS[uttt.PosType, *mcts.NodeStats, mcts.Result, *uttt.Position](ucbmcts),
+			bench.ExtMCTS[uttt.PosType, *mcts.VarianceStats, mcts.Result, *uttt.Position](variancemcts),
+			opts,
+		)
+
+	default:
+		fmt.Printf("Unknown opponent %q, expected rave, ucb1-tuned or ucbv\n", *opponent)
+		os.Exit(2)
+	}
+
+	if *saveToFile != "" {
+		f, err := os.Create(*saveToFile)
+		if err != nil {
+			panic(err)
+		}
+		defer f.Close()
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			panic(err)
+		}
+		_, err = f.WriteString(string(jsonData))
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Printf("Results saved to %s\n", *saveToFile)
+	}
 }
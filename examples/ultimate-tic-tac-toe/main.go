@@ -13,6 +13,7 @@ Example below shows how to run a search and get the result.
 */
 
 import (
+	"flag"
 	"fmt"
 
 	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
@@ -21,6 +22,9 @@ import (
 )
 
 func main() {
+	rollout := flag.String("rollout", "random", "Rollout moves: random, mast or nst")
+	flag.Parse()
+
 	fmt.Println("Ultimate Tic Tac Toe MCTS UCB1 Example")
 
 	// Create a new UTTT MCTS instance
@@ -35,6 +39,15 @@ func main() {
 	// Set UCB exploration parameter, default is 0.75
 	tree.Strategy().SetExplorationParam(0.35)
 
+	// Optionally choose the rollout moves with the policy learned during the search,
+	// MAST (move averages) or NST (averages of the move pairs)
+	switch *rollout {
+	case "mast":
+		tree.Ops().SetRolloutPolicy(mcts.NewMAST[uttt.PosType]())
+	case "nst":
+		tree.Ops().SetRolloutPolicy(mcts.NewNST[uttt.PosType](2))
+	}
+
 	// Run the search, will block until done
 	tree.Search()
 
@@ -3,7 +3,7 @@ package main
 /*
 
 This example shows the relative speed up of the search using
-both tree and root parallel multithreading policies.
+tree, root and leaf parallel multithreading policies.
 
 */
 
@@ -41,17 +41,17 @@ func (s *SearchStats) Set(i, cps, depth, pvlen int, collfactor float64, rootVisi
 	s.RootVisits[i] = rootVisits
 }
 
-func Summary(nthreads int, tree, root *SearchStats) {
+func Summary(nthreads int, tree, root, leaf *SearchStats) {
 	fmt.Println("Summary")
 	for i := range nthreads {
-		fmt.Printf("Threads: %d (tree, root)\n", i+1)
-		fmt.Printf("\tDepth: %12d - %d\n", tree.Depth[i], root.Depth[i])
-		fmt.Printf("\tCps: %14d - %d\n", tree.Cps[i], root.Cps[i])
-		fmt.Printf("\tPvLen: %12d - %d\n", tree.PvLen[i], root.PvLen[i])
-		fmt.Printf("\tColls: %11.2f%% - %.2f%%\n", tree.Colls[i]*100.0, root.Colls[i]*100)
-		fmt.Printf("\tRootVisits: %7d - %d\n", tree.RootVisits[i], root.RootVisits[i])
-		fmt.Printf("\tSpeedup: %10.2f - %.2f\n", float64(tree.Cps[i])/float64(tree.Cps[0]),
-			float64(root.Cps[i])/float64(root.Cps[0]))
+		fmt.Printf("Threads: %d (tree, root, leaf)\n", i+1)
+		fmt.Printf("\tDepth: %12d - %d - %d\n", tree.Depth[i], root.Depth[i], leaf.Depth[i])
+		fmt.Printf("\tCps: %14d - %d - %d\n", tree.Cps[i], root.Cps[i], leaf.Cps[i])
+		fmt.Printf("\tPvLen: %12d - %d - %d\n", tree.PvLen[i], root.PvLen[i], leaf.PvLen[i])
+		fmt.Printf("\tColls: %11.2f%% - %.2f%% - %.2f%%\n", tree.Colls[i]*100.0, root.Colls[i]*100, leaf.Colls[i]*100)
+		fmt.Printf("\tRootVisits: %7d - %d - %d\n", tree.RootVisits[i], root.RootVisits[i], leaf.RootVisits[i])
+		fmt.Printf("\tSpeedup: %10.2f - %.2f - %.2f\n", float64(tree.Cps[i])/float64(tree.Cps[0]),
+			float64(root.Cps[i])/float64(root.Cps[0]), float64(leaf.Cps[i])/float64(leaf.Cps[0]))
 	}
 	fmt.Println()
 }
@@ -72,6 +72,7 @@ func main() {
 
 	rootParallelStats := NewSearchStats(MaxThreads)
 	treeParallelStats := NewSearchStats(MaxThreads)
+	leafParallelStats := NewSearchStats(MaxThreads)
 
 	for i := range MaxThreads {
 		threads := i + 1
@@ -98,8 +99,19 @@ func main() {
 		res = tree.SearchResult(bestChildPolicy)
 		treeParallelStats.Set(i, int(res.Cps), res.Depth, len(res.Lines[0].Pv), tree.CollisionFactor(), tree.Root.Stats.N())
 		fmt.Printf("Tree parallel: %s\n", res.String())
+
+		// Discard current search tree
+		tree.Reset()
+
+		// Leaf-parallel simulates every leaf on all threads, so it has no collisions,
+		// but the tree grows only as fast as a single thread can select
+		tree.SetMultithreadPolicy(mcts.MultithreadLeafParallel)
+		tree.Search()
+		res = tree.SearchResult(bestChildPolicy)
+		leafParallelStats.Set(i, int(res.Cps), res.Depth, len(res.Lines[0].Pv), tree.CollisionFactor(), tree.Root.Stats.N())
+		fmt.Printf("Leaf parallel: %s\n", res.String())
 	}
 
 	// Compare the results
-	Summary(MaxThreads, treeParallelStats, rootParallelStats)
+	Summary(MaxThreads, treeParallelStats, rootParallelStats, leafParallelStats)
 }
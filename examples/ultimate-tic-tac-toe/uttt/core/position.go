@@ -132,6 +132,22 @@ func (p *Position) BigIndex() PosType {
 	return p.nextBigIndex
 }
 
+// Hash of the current position (pieces, next big index and side to move),
+// computed from the bitboards on every call
+func (p *Position) Hash() uint64 {
+	hash := uint64(p.nextBigIndex)<<1 | uint64(_boolToInt(bool(p.Turn())))
+	for side := range p.bitboards {
+		for i := range p.bitboards[side] {
+			// splitmix64 step, to spread the 9-bit boards over the whole hash
+			hash += uint64(p.bitboards[side][i]) + 0x9e3779b97f4a7c15
+			hash = (hash ^ (hash >> 30)) * 0xbf58476d1ce4e5b9
+			hash = (hash ^ (hash >> 27)) * 0x94d049bb133111eb
+			hash ^= hash >> 31
+		}
+	}
+	return hash
+}
+
 // func (p *Position) _UpdateBigPosHash(state PositionState, bigIndex PosType) {
 // 	// Update the hash
 // 	idx := -1
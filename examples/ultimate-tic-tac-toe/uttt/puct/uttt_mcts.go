@@ -0,0 +1,299 @@
+package puct_uttt
+
+/*
+
+Ultimate Tic Tac Toe MCTS implementation with PUCT selection
+
+Compared to the UCB example, the operations also implement mcts.PriorGameOperations,
+that is the Priors method, which gives each move a heuristic weight (move ordering).
+The search then spends most of its playouts on the moves with high prior,
+until their value says otherwise.
+
+*/
+
+import (
+	"math/rand"
+	"unsafe"
+
+	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
+	mcts "github.com/IlikeChooros/go-mcts/pkg/mcts"
+)
+
+// Actual UTTT mcts implementation, using mcts.PuctStats (meets mcts.PuctStatsLike)
+type UtttMCTS struct {
+	mcts.MCTS[uttt.PosType, *mcts.PuctStats, mcts.Result, *UtttOperations, *mcts.PUCT[uttt.PosType, *mcts.PuctStats, mcts.Result, *UtttOperations]]
+}
+
+func NewUtttMCTS(position uttt.Position) *UtttMCTS {
+	// Each mcts instance must have its own operations instance
+	return &UtttMCTS{
+		MCTS: *mcts.NewMTCS(
+			mcts.NewPUCT[uttt.PosType, *mcts.PuctStats, mcts.Result, *UtttOperations](1.0),
+			NewUtttOps(position),
+			mcts.MultithreadTreeParallel,
+			&mcts.PuctStats{},
+		),
+	}
+}
+
+// Start the search
+func (tree *UtttMCTS) Search() {
+
+	// Run the search
+	tree.SearchMultiThreaded()
+
+	// Wait for the search to end
+	tree.Synchronize()
+}
+
+// Remove current game tree, resets the tree's and game ops's state
+func (tree *UtttMCTS) Reset() {
+	tree.MCTS.Reset(tree.Ops().position.IsTerminated(), &mcts.PuctStats{})
+}
+
+// Set the position
+func (tree *UtttMCTS) SetPosition(position uttt.Position) {
+	tree.Ops().position = position
+	tree.Reset()
+}
+
+func (mcts *UtttMCTS) SetNotation(notation string) error {
+	defer mcts.Reset()
+	return mcts.Ops().position.FromNotation(notation)
+}
+
+func (tree *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) uttt.SearchResult {
+
+	multipv := tree.MultiPv(pvPolicy)
+	result := uttt.SearchResult{
+		Cps:    tree.Cps(),
+		Depth:  tree.MaxDepth(),
+		Cycles: tree.Root.Stats.N(),
+		Lines:  make([]uttt.EngineLine, len(multipv)),
+		Turn:   tree.Ops().rootSide,
+		Size:   tree.Size(),
+		Memory: uint64(unsafe.Sizeof(mcts.NodeBase[uttt.PosType, *mcts.PuctStats]{})) * uint64(tree.Size()),
+	}
+
+	for i := range len(multipv) {
+		pvResult := multipv[i]
+		line := &result.Lines[i]
+		line.Pv = pvResult.Pv
+
+		// Set the score
+		if pvResult.Terminal {
+			if pvResult.Draw {
+				line.ScoreType = uttt.ValueScore
+				line.Value = 50
+			} else {
+				line.ScoreType = uttt.MateScore
+				line.Value = len(pvResult.Pv)
+
+				// If the game ends on our turn, we are losing
+				if line.Value%2 == 0 {
+					line.Value = -line.Value
+				}
+			}
+		} else {
+			line.ScoreType = uttt.ValueScore
+			if pvResult.Root.Stats.N() == 0 {
+				line.Value = 50
+			} else {
+				line.Value = int(100 * pvResult.Root.Stats.AvgQ())
+			}
+		}
+	}
+	return result
+}
+
+// Must meet mcts.GameOperations, see the UCB example for the description
+// of the required methods.
+//
+// optional:
+//
+// - SetRand(rand.Rand) - (from math.rand), sets a random generator created in the search thread.
+//
+// - Priors(parent, priors) - heuristic weights of the parent's children (mcts.PriorGameOperations),
+// called right after ExpandNode, the weights are normalized by the search
+type UtttOperations struct {
+	position uttt.Position
+	// This is needed for the SearchResult to work properly, since
+	// I allow calling that function during the search (ops.position.Turn() may return wrong one)
+	rootSide uttt.TurnType
+	// Will be set by search thread, with 'SetRand'
+	random *rand.Rand
+}
+
+func NewUtttOps(pos uttt.Position) *UtttOperations {
+	return &UtttOperations{
+		position: pos,
+		rootSide: pos.Turn(),
+	}
+}
+
+func (ops *UtttOperations) Reset() {
+	ops.rootSide = ops.position.Turn()
+}
+
+func (ops *UtttOperations) ExpandNode(node *mcts.NodeBase[uttt.PosType, *mcts.PuctStats]) uint32 {
+
+	moves := ops.position.GenerateMoves()
+	node.Children = make([]mcts.NodeBase[uttt.PosType, *mcts.PuctStats], moves.Size)
+
+	for i, m := range moves.Slice() {
+		ops.position.MakeMove(m)
+		isTerminal := ops.position.IsTerminated()
+		ops.position.Undo()
+
+		node.Children[i] = *mcts.NewBaseNode(node, m, isTerminal, &mcts.PuctStats{})
+	}
+
+	return uint32(moves.Size)
+}
+
+// Heuristic move weights:
+//   - winning the game is by far the best
+//   - winning a small board is good
+//   - sending the enemy to a resolved board (letting them play anywhere) is bad
+//   - center and corners of the small board are slightly better than edges
+func (ops *UtttOperations) Priors(node *mcts.NodeBase[uttt.PosType, *mcts.PuctStats], priors []float64) {
+	for i := range node.Children {
+		priors[i] = ops.movePrior(node.Children[i].Move)
+	}
+}
+
+func (ops *UtttOperations) movePrior(move uttt.PosType) float64 {
+	weight := 1.0
+	switch move.SmallIndex() {
+	case 4:
+		weight = 1.5
+	case 0, 2, 6, 8:
+		weight = 1.2
+	}
+
+	bigIndex := move.BigIndex()
+	before := ops.position.BigPositionState()[bigIndex]
+
+	ops.position.MakeMove(move)
+	defer ops.position.Undo()
+
+	if ops.position.IsTerminated() {
+		if ops.position.Termination() != uttt.TerminationDraw {
+			return 100
+		}
+		return weight
+	}
+
+	if after := ops.position.BigPositionState()[bigIndex]; after != before && after != uttt.PositionDraw {
+		weight *= 8
+	}
+
+	if ops.position.BigIndex() == uttt.PosIndexIllegal {
+		weight *= 0.5
+	}
+	return weight
+}
+
+// Static evaluation used instead of the rollouts (see mcts.Evaluator), counts the small
+// boards won by each side. Set it with tree.SetEvaluator(StaticEvaluator{}).
+type StaticEvaluator struct{}
+
+func (StaticEvaluator) Evaluate(ops *UtttOperations, leaf *mcts.NodeBase[uttt.PosType, *mcts.PuctStats]) mcts.Result {
+	pos := &ops.position
+	ourState, enemyState := uttt.PositionCircleWon, uttt.PositionCrossWon
+	if pos.Turn() == uttt.CrossTurn {
+		ourState, enemyState = enemyState, ourState
+	}
+
+	if pos.IsTerminated() {
+		switch pos.Termination() {
+		case uttt.TerminationDraw:
+			return 0.5
+		case uttt.TerminationCircleWon:
+			if ourState == uttt.PositionCircleWon {
+				return 1.0
+			}
+		case uttt.TerminationCrossWon:
+			if ourState == uttt.PositionCrossWon {
+				return 1.0
+			}
+		}
+		return 0.0
+	}
+
+	balance := 0
+	for _, state := range pos.BigPositionState() {
+		if state == ourState {
+			balance++
+		} else if state == enemyState {
+			balance--
+		}
+	}
+	return mcts.Result(min(0.95, max(0.05, 0.5+0.1*float64(balance))))
+}
+
+func (ops *UtttOperations) Traverse(move uttt.PosType) {
+	ops.position.MakeMove(move)
+}
+
+func (ops *UtttOperations) BackTraverse() {
+	ops.position.Undo()
+}
+
+// Play the game until a terminal node is reached
+// The result is relative to the 'starting' node of the rollout
+func (ops *UtttOperations) Rollout() mcts.Result {
+	var moves *uttt.MoveList
+	var move uttt.PosType
+	var result mcts.Result = 0.5
+	var moveCount int = 0
+	leafTurn := ops.position.Turn()
+
+	for !ops.position.IsTerminated() {
+		moveCount++
+		moves = ops.position.GenerateMoves()
+
+		// Choose at random move
+		move = moves.Moves[ops.random.Int31()%int32(moves.Size)]
+		ops.position.MakeMove(move)
+	}
+
+	// If that's not a draw
+	if t := ops.position.Termination(); (t == uttt.TerminationCircleWon && leafTurn == uttt.CircleTurn) ||
+		(t == uttt.TerminationCrossWon && leafTurn == uttt.CrossTurn) {
+		result = 1.0
+		// We lost
+	} else if t != uttt.TerminationDraw {
+		result = 0.0
+	}
+
+	// Undo the moves
+	for range moveCount {
+		ops.position.Undo()
+	}
+
+	return result
+}
+
+// Sets the random number generator, called at the begining of the search
+func (ops *UtttOperations) SetRand(r *rand.Rand) {
+	ops.random = r
+}
+
+// It should return a deep copy of the ops object
+func (ops UtttOperations) Clone() *UtttOperations {
+	return &UtttOperations{
+		position: *ops.position.Clone(),
+		rootSide: ops.rootSide,
+	}
+}
+
+// Added for benchmarking purposes
+func (ops *UtttOperations) Position() *uttt.Position {
+	return &ops.position
+}
+
+func (ops *UtttOperations) SetPosition(pos uttt.Position) {
+	ops.position = pos
+	ops.rootSide = pos.Turn()
+}
@@ -0,0 +1,90 @@
+package puct_uttt
+
+import (
+	"math"
+	"testing"
+
+	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
+)
+
+func TestMCTSPriors(t *testing.T) {
+	pos, err := uttt.FromNotation("xx7/o8/o8/9/9/9/9/9/9 x -")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	tree.Limits().SetCycles(2000)
+	tree.Search()
+
+	// Winning the small board should have the highest prior
+	winning := uttt.MakeMove(0, 2)
+	best, sum := tree.Root.Children[0], 0.0
+	for _, child := range tree.Root.Children {
+		sum += child.Stats.Prior()
+		if child.Stats.Prior() > best.Stats.Prior() {
+			best = child
+		}
+	}
+
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("Priors should sum up to 1, got %f", sum)
+	}
+
+	if best.Move != winning {
+		t.Errorf("Expected %s to have the highest prior, got %s", winning, best.Move)
+	}
+}
+
+func TestMCTSSearch(t *testing.T) {
+	pos := uttt.NewPosition()
+	err := pos.FromNotation(uttt.StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mcts := NewUtttMCTS(*pos)
+
+	// Set short cycle limit for testing
+	mcts.Limits().SetCycles(10000).SetThreads(2)
+	originalNotation := pos.Notation()
+
+	// Run search
+	mcts.Search()
+
+	// Check that search actually ran
+	if mcts.Root.Stats.N() == 0 {
+		t.Error("Root should have been visited during search")
+	}
+
+	// Position should be restored
+	if pos.Notation() != originalNotation {
+		t.Error("Position not restored after search")
+	}
+
+	// Should have children after search
+	if mcts.Root.Children == nil {
+		t.Error("Root should have children after search")
+	}
+}
+
+func TestMCTSStaticEvaluator(t *testing.T) {
+	// Cross won the first board, circle to move
+	pos, err := uttt.FromNotation("xxx6/o8/o8/9/9/9/9/9/9 o -")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewUtttMCTS(*pos)
+	if v := (StaticEvaluator{}).Evaluate(tree.Ops(), tree.Root); v >= 0.5 {
+		t.Errorf("Circle should be losing, got %f", v)
+	}
+
+	tree.SetEvaluator(StaticEvaluator{})
+	tree.Limits().SetCycles(3000)
+	tree.Search()
+
+	if tree.Root.Stats.N() == 0 {
+		t.Error("Root should have been visited during search")
+	}
+}
@@ -102,6 +102,36 @@ func (tree *UtttMCTS) SetPosition(position uttt.Position) {
 	tree.Reset()
 }
 
+// UTTT mcts with GRAVE selection, sharing the stats, game result and operations with RAVE
+type UtttGRAVE struct {
+	mcts.MCTS[
+		uttt.PosType, *mcts.RaveStats, *UtttGameResult,
+		*UtttOperations, *mcts.GRAVE[uttt.PosType, *mcts.RaveStats, *UtttGameResult, *UtttOperations]]
+}
+
+func NewUtttGRAVE(position uttt.Position) *UtttGRAVE {
+	// Each mcts instance must have its own operations instance
+	return &UtttGRAVE{
+		MCTS: *mcts.NewMTCS(
+			mcts.NewGRAVE[uttt.PosType, *mcts.RaveStats, *UtttGameResult, *UtttOperations](),
+			NewUtttOps(position),
+			mcts.MultithreadTreeParallel,
+			&mcts.RaveStats{},
+		),
+	}
+}
+
+// Start the search
+func (tree *UtttGRAVE) Search() {
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+}
+
+// Remove current game tree, resets the tree's and game ops's state
+func (tree *UtttGRAVE) Reset() {
+	tree.MCTS.Reset(tree.Ops().position.IsTerminated(), &mcts.RaveStats{})
+}
+
 func (mcts *UtttMCTS) SetNotation(notation string) error {
 	defer mcts.Reset()
 	return mcts.Ops().position.FromNotation(notation)
@@ -152,3 +152,84 @@ func TestMCTSSearch(t *testing.T) {
 		t.Error("Root should have children after search")
 	}
 }
+
+func TestGRAVESearch(t *testing.T) {
+	pos := uttt.NewPosition()
+	originalNotation := pos.Notation()
+
+	tree := NewUtttGRAVE(*pos)
+	tree.Limits().SetCycles(10000).SetThreads(2)
+	tree.Search()
+
+	if tree.Root.Stats.N() == 0 {
+		t.Error("Root should have been visited during search")
+	}
+	if tree.Ops().Position().Notation() != originalNotation {
+		t.Error("Position not restored after search")
+	}
+
+	// AMAF statistics are gathered the same way as in RAVE
+	amaf := int32(0)
+	for _, child := range tree.Root.Children {
+		amaf += child.Stats.NRAVE()
+	}
+	if amaf == 0 {
+		t.Error("Root children should have AMAF statistics")
+	}
+}
+
+// Nodes of the tree with children, up to the given depth
+func expandedNodes(node *mcts.NodeBase[uttt.PosType, *mcts.RaveStats], depth int) []*mcts.NodeBase[uttt.PosType, *mcts.RaveStats] {
+	if depth == 0 || !node.Expanded() || len(node.Children) == 0 {
+		return nil
+	}
+	nodes := []*mcts.NodeBase[uttt.PosType, *mcts.RaveStats]{node}
+	for i := range node.Children {
+		nodes = append(nodes, expandedNodes(&node.Children[i], depth-1)...)
+	}
+	return nodes
+}
+
+func TestGRAVEReference(t *testing.T) {
+	tree := NewUtttMCTS(*uttt.NewPosition())
+	tree.Limits().SetCycles(20000)
+	tree.Search()
+
+	rave := tree.Strategy()
+	grave := mcts.NewGRAVE[uttt.PosType, *mcts.RaveStats, *UtttGameResult, *UtttOperations]().
+		SetExplorationParam(rave.ExplorationParam).
+		SetBetaFunction(rave.BetaFunction)
+
+	nodes := expandedNodes(tree.Root, 4)
+	if len(nodes) < 10 {
+		t.Fatalf("Expected a deeper tree, got %d expanded nodes", len(nodes))
+	}
+
+	// Every node has more than 0 playouts, so GRAVE uses the node's own AMAF statistics, like RAVE
+	grave.SetRef(0)
+	for _, node := range nodes {
+		if g, r := grave.Select(node, tree.Root), rave.Select(node, tree.Root); g != r {
+			t.Fatalf("Ref=0: expected the RAVE choice %s, got %s", r.Move, g.Move)
+		}
+	}
+
+	// Root's grandchildren use the root's AMAF statistics (same player to move), with a high Ref
+	grave.SetRef(1 << 30)
+	differs := false
+	for _, child := range tree.Root.Children {
+		for i := range child.Children {
+			node := &child.Children[i]
+			if !node.Expanded() || len(node.Children) == 0 {
+				continue
+			}
+			selected := grave.Select(node, tree.Root)
+			if selected.Parent != node {
+				t.Fatalf("Expected a child of the node, got %s", selected.Move)
+			}
+			differs = differs || selected != rave.Select(node, tree.Root)
+		}
+	}
+	if !differs {
+		t.Error("Expected the root's AMAF statistics to change some of the choices")
+	}
+}
@@ -8,6 +8,7 @@ Ultimate Tic Tac Toe MCTS implementation with UCB1 selection
 
 import (
 	"math/rand"
+	"slices"
 	"time"
 	"unsafe"
 
@@ -21,15 +22,21 @@ type UtttMCTS struct {
 }
 
 func NewUtttMCTS(position uttt.Position) *UtttMCTS {
+	// Nodes are allocated in blocks, and reused after MakeMove and Reset
+	ops := NewUtttOps(position)
+	ops.pool = mcts.NewNodePool[uttt.PosType, mcts.NodeStats](nodePoolBlockSize)
+
 	// Each mcts instance must have its own operations instance
-	return &UtttMCTS{
+	tree := &UtttMCTS{
 		MCTS: *mcts.NewMTCS(
 			mcts.NewUCB1[uttt.PosType, *mcts.NodeStats, mcts.Result, *UtttOperations](0.45),
-			NewUtttOps(position),
+			ops,
 			mcts.MultithreadTreeParallel,
 			&mcts.NodeStats{},
 		),
 	}
+	tree.SetNodePool(ops.pool)
+	return tree
 }
 
 // Start the search
@@ -122,6 +129,11 @@ func (tree *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) uttt.SearchRes
 //
 // - SetRand(rand.Rand) - (from math.rand), sets a random generator created in the search thread.
 // Add this function if you want to perform light playouts (making random moves)
+//
+// - Hash() uint64 - hash of the current position, enables transposition detection
+// with tree.SetTranspositions(true)
+//
+// Rollouts choose the moves at random, or with the policy learned during the search (see SetRolloutPolicy)
 type UtttOperations struct {
 	position uttt.Position
 	// This is needed for the SearchResult to work properly, since
@@ -129,8 +141,17 @@ type UtttOperations struct {
 	rootSide uttt.TurnType
 	// Will be set by search thread, with 'SetRand'
 	random *rand.Rand
+	// Optional node allocator, shared by the clones
+	pool *mcts.NodePool[uttt.PosType, mcts.NodeStats, *mcts.NodeStats]
+	// Optional rollout policy, shared by the clones
+	mast *mcts.MAST[uttt.PosType]
+	// Moves from the root to the current position, followed by the rollout moves
+	path []uttt.PosType
 }
 
+// Number of nodes allocated at once by the node pool
+const nodePoolBlockSize = 1 << 14
+
 func NewUtttOps(pos uttt.Position) *UtttOperations {
 	return &UtttOperations{
 		position: pos,
@@ -146,14 +167,18 @@ func (ops *UtttOperations) Reset() {
 func (ops *UtttOperations) ExpandNode(node *mcts.NodeBase[uttt.PosType, *mcts.NodeStats]) uint32 {
 
 	moves := ops.position.GenerateMoves()
-	node.Children = make([]mcts.NodeBase[uttt.PosType, *mcts.NodeStats], moves.Size)
+	if ops.pool != nil {
+		node.Children = ops.pool.Children(int(moves.Size))
+	} else {
+		node.Children = make([]mcts.NodeBase[uttt.PosType, *mcts.NodeStats], moves.Size)
+	}
 
 	for i, m := range moves.Slice() {
 		ops.position.MakeMove(m)
 		isTerminal := ops.position.IsTerminated()
 		ops.position.Undo()
 
-		node.Children[i] = *mcts.NewBaseNode(node, m, isTerminal, &mcts.NodeStats{})
+		node.Children[i] = *mcts.NewBaseNode(node, m, isTerminal, ops.newStats())
 	}
 
 	return uint32(moves.Size)
@@ -161,10 +186,25 @@ func (ops *UtttOperations) ExpandNode(node *mcts.NodeBase[uttt.PosType, *mcts.No
 
 func (ops *UtttOperations) Traverse(move uttt.PosType) {
 	ops.position.MakeMove(move)
+	ops.path = append(ops.path, move)
 }
 
 func (ops *UtttOperations) BackTraverse() {
 	ops.position.Undo()
+	// Backpropagation undoes the root as well
+	if len(ops.path) > 0 {
+		ops.path = ops.path[:len(ops.path)-1]
+	}
+}
+
+// Chooses the rollout moves with the MAST (or NST) policy, updated after every rollout,
+// nil switches back to the random moves
+func (ops *UtttOperations) SetRolloutPolicy(policy *mcts.MAST[uttt.PosType]) {
+	ops.mast = policy
+}
+
+func (ops *UtttOperations) RolloutPolicy() *mcts.MAST[uttt.PosType] {
+	return ops.mast
 }
 
 // Play the game until a terminal node is reached
@@ -175,13 +215,20 @@ func (ops *UtttOperations) Rollout() mcts.Result {
 	var result mcts.Result = 0.5
 	var moveCount int = 0
 	leafTurn := ops.position.Turn()
+	treeMoves := len(ops.path)
 
 	for !ops.position.IsTerminated() {
 		moveCount++
 		moves = ops.position.GenerateMoves()
 
-		// Choose at random move
-		move = moves.Moves[ops.random.Int31()%int32(moves.Size)]
+		if ops.mast != nil {
+			// Choose the move with the learned policy
+			move = moves.Moves[ops.mast.Choose(ops.random, ops.path, moves.Slice())]
+			ops.path = append(ops.path, move)
+		} else {
+			// Choose at random move
+			move = moves.Moves[ops.random.Int31()%int32(moves.Size)]
+		}
 		ops.position.MakeMove(move)
 	}
 
@@ -194,6 +241,16 @@ func (ops *UtttOperations) Rollout() mcts.Result {
 		result = 0.0
 	}
 
+	if ops.mast != nil {
+		// The policy expects the result of the first move's player (the leaf's side after even moves)
+		if treeMoves%2 == 0 {
+			ops.mast.Update(ops.path, result)
+		} else {
+			ops.mast.Update(ops.path, 1-result)
+		}
+		ops.path = ops.path[:treeMoves]
+	}
+
 	// Undo the moves
 	for range moveCount {
 		ops.position.Undo()
@@ -202,6 +259,11 @@ func (ops *UtttOperations) Rollout() mcts.Result {
 	return result
 }
 
+// Hash of the current position, used by the transposition-aware search
+func (ops *UtttOperations) Hash() uint64 {
+	return ops.position.Hash()
+}
+
 func (ops *UtttOperations) SetRand(r *rand.Rand) {
 	ops.random = r
 }
@@ -210,7 +272,17 @@ func (ops UtttOperations) Clone() *UtttOperations {
 	return &UtttOperations{
 		position: *ops.position.Clone(),
 		rootSide: ops.rootSide,
+		pool:     ops.pool,
+		mast:     ops.mast,
+		path:     slices.Clone(ops.path),
+	}
+}
+
+func (ops *UtttOperations) newStats() *mcts.NodeStats {
+	if ops.pool != nil {
+		return ops.pool.Stats()
 	}
+	return &mcts.NodeStats{}
 }
 
 // Added for benchmarking purposes
@@ -221,4 +293,5 @@ func (ops *UtttOperations) Position() *uttt.Position {
 func (ops *UtttOperations) SetPosition(pos uttt.Position) {
 	ops.position = pos
 	ops.rootSide = pos.Turn()
+	ops.path = ops.path[:0]
 }
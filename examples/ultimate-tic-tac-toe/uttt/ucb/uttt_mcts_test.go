@@ -408,6 +408,72 @@ func TestMCTSContextCancellation(t *testing.T) {
 	}
 }
 
+func TestMCTSTranspositions(t *testing.T) {
+	pos, err := uttt.FromNotation(uttt.StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := NewUtttMCTS(*pos)
+	engine.SetTranspositions(true)
+	engine.Limits().SetThreads(4).SetCycles(40000)
+	engine.Search()
+
+	// Every expanded child is stored (or linked) in the table, and
+	// transpositions make the table smaller than the tree
+	table := engine.Transpositions()
+	if table.Size() == 0 || table.Size() >= engine.Size() {
+		t.Errorf("Unexpected table size %d (tree size %d)", table.Size(), engine.Size())
+	}
+
+	result, _ := engine.SearchResult(mcts.BestChildMostVisits).MainLine()
+	if len(result.Pv) == 0 {
+		t.Error("Pv shouldn't be empty after search")
+	}
+}
+
+func TestMCTSRolloutPolicy(t *testing.T) {
+	pos, err := uttt.FromNotation(uttt.StartingPosition)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rollout from a tree node, the policy learns both the tree and the rollout moves
+	policy := mcts.NewMAST[uttt.PosType]()
+	ops := &UtttOperations{position: *pos, random: rand.New(rand.NewSource(22))}
+	ops.SetRolloutPolicy(policy)
+	first := pos.GenerateMoves().Moves[0]
+	ops.Traverse(first)
+	notation := ops.position.Notation()
+
+	result := ops.Rollout()
+	if result < 0 || result > 1 {
+		t.Fatalf("Invalid rollout result: %f", result)
+	}
+	if ops.position.Notation() != notation || len(ops.path) != 1 {
+		t.Fatalf("Position and path not restored after rollout, path %v", ops.path)
+	}
+
+	// The tree move was made by the side not to move at the leaf
+	if q, n := policy.Stats(nil, first, 1); n != 1 || math.Abs(float64(q+result-1)) > 1e-3 {
+		t.Fatalf("Expected the tree move to be credited with %f, got %f (%d visits)", 1-result, q, n)
+	}
+	ops.BackTraverse()
+
+	engine := NewUtttMCTS(*pos)
+	engine.Ops().SetRolloutPolicy(mcts.NewNST[uttt.PosType](2))
+	engine.Limits().SetThreads(4).SetCycles(20000)
+	engine.Search()
+
+	line, _ := engine.SearchResult(mcts.BestChildMostVisits).MainLine()
+	if len(line.Pv) == 0 || line.Bestmove == uttt.PosIllegal {
+		t.Error("Expected a main line after the search with the rollout policy")
+	}
+	if _, n := engine.Ops().RolloutPolicy().Stats(nil, line.Bestmove, 1); n == 0 {
+		t.Error("Expected the best move in the policy's table")
+	}
+}
+
 func BenchmarkMCTSRollout(b *testing.B) {
 	pos := uttt.NewPosition()
 	err := pos.FromNotation(uttt.StartingPosition)
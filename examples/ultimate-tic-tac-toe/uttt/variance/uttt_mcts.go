@@ -0,0 +1,222 @@
+package variance_uttt
+
+/*
+
+Ultimate Tic Tac Toe MCTS implementation with variance-aware selection (UCB1-Tuned or UCB-V)
+
+Compared to the UCB example, the nodes use mcts.VarianceStats, which also track the sum of
+squared results, so the strategy can explore less the moves with consistent rollout results.
+
+*/
+
+import (
+	"math/rand"
+	"unsafe"
+
+	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
+	mcts "github.com/IlikeChooros/go-mcts/pkg/mcts"
+)
+
+// Selection strategy of the tree, either mcts.UCB1Tuned or mcts.UCBV
+type Strategy = mcts.StrategyLike[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations]
+
+// Actual UTTT mcts implementation, using mcts.VarianceStats (meets mcts.VarianceStatsLike)
+type UtttMCTS struct {
+	mcts.MCTS[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations, Strategy]
+}
+
+// UCB1-Tuned strategy, with the original exploration constant
+func NewUCB1Tuned() *mcts.UCB1Tuned[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations] {
+	return mcts.NewUCB1Tuned[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations](1.0)
+}
+
+// UCB-V strategy, with the parameters from the paper
+func NewUCBV() *mcts.UCBV[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations] {
+	return mcts.NewUCBV[uttt.PosType, *mcts.VarianceStats, mcts.Result, *UtttOperations](1.2, 1.0)
+}
+
+func NewUtttMCTS(position uttt.Position, strategy Strategy) *UtttMCTS {
+	// Each mcts instance must have its own operations instance
+	return &UtttMCTS{
+		MCTS: *mcts.NewMTCS(
+			strategy,
+			NewUtttOps(position),
+			mcts.MultithreadTreeParallel,
+			&mcts.VarianceStats{},
+		),
+	}
+}
+
+// Start the search
+func (tree *UtttMCTS) Search() {
+
+	// Run the search
+	tree.SearchMultiThreaded()
+
+	// Wait for the search to end
+	tree.Synchronize()
+}
+
+// Remove current game tree, resets the tree's and game ops's state
+func (tree *UtttMCTS) Reset() {
+	tree.MCTS.Reset(tree.Ops().position.IsTerminated(), &mcts.VarianceStats{})
+}
+
+// Set the position
+func (tree *UtttMCTS) SetPosition(position uttt.Position) {
+	tree.Ops().position = position
+	tree.Reset()
+}
+
+func (mcts *UtttMCTS) SetNotation(notation string) error {
+	defer mcts.Reset()
+	return mcts.Ops().position.FromNotation(notation)
+}
+
+func (tree *UtttMCTS) SearchResult(pvPolicy mcts.BestChildPolicy) uttt.SearchResult {
+
+	multipv := tree.MultiPv(pvPolicy)
+	result := uttt.SearchResult{
+		Cps:    tree.Cps(),
+		Depth:  tree.MaxDepth(),
+		Cycles: tree.Root.Stats.N(),
+		Lines:  make([]uttt.EngineLine, len(multipv)),
+		Turn:   tree.Ops().rootSide,
+		Size:   tree.Size(),
+		Memory: uint64(unsafe.Sizeof(mcts.NodeBase[uttt.PosType, *mcts.VarianceStats]{})) * uint64(tree.Size()),
+	}
+
+	for i := range len(multipv) {
+		pvResult := multipv[i]
+		line := &result.Lines[i]
+		line.Pv = pvResult.Pv
+
+		// Set the score
+		if pvResult.Terminal {
+			if pvResult.Draw {
+				line.ScoreType = uttt.ValueScore
+				line.Value = 50
+			} else {
+				line.ScoreType = uttt.MateScore
+				line.Value = len(pvResult.Pv)
+
+				// If the game ends on our turn, we are losing
+				if line.Value%2 == 0 {
+					line.Value = -line.Value
+				}
+			}
+		} else {
+			line.ScoreType = uttt.ValueScore
+			if pvResult.Root.Stats.N() == 0 {
+				line.Value = 50
+			} else {
+				line.Value = int(100 * pvResult.Root.Stats.AvgQ())
+			}
+		}
+	}
+	return result
+}
+
+// Must meet mcts.GameOperations, see the UCB example for the description
+// of the required methods.
+type UtttOperations struct {
+	position uttt.Position
+	// This is needed for the SearchResult to work properly, since
+	// I allow calling that function during the search (ops.position.Turn() may return wrong one)
+	rootSide uttt.TurnType
+	// Will be set by search thread, with 'SetRand'
+	random *rand.Rand
+}
+
+func NewUtttOps(pos uttt.Position) *UtttOperations {
+	return &UtttOperations{
+		position: pos,
+		rootSide: pos.Turn(),
+	}
+}
+
+func (ops *UtttOperations) Reset() {
+	ops.rootSide = ops.position.Turn()
+}
+
+func (ops *UtttOperations) ExpandNode(node *mcts.NodeBase[uttt.PosType, *mcts.VarianceStats]) uint32 {
+
+	moves := ops.position.GenerateMoves()
+	node.Children = make([]mcts.NodeBase[uttt.PosType, *mcts.VarianceStats], moves.Size)
+
+	for i, m := range moves.Slice() {
+		ops.position.MakeMove(m)
+		isTerminal := ops.position.IsTerminated()
+		ops.position.Undo()
+
+		node.Children[i] = *mcts.NewBaseNode(node, m, isTerminal, &mcts.VarianceStats{})
+	}
+
+	return uint32(moves.Size)
+}
+
+func (ops *UtttOperations) Traverse(move uttt.PosType) {
+	ops.position.MakeMove(move)
+}
+
+func (ops *UtttOperations) BackTraverse() {
+	ops.position.Undo()
+}
+
+// Play the game until a terminal node is reached
+// The result is relative to the 'starting' node of the rollout
+func (ops *UtttOperations) Rollout() mcts.Result {
+	var moves *uttt.MoveList
+	var move uttt.PosType
+	var result mcts.Result = 0.5
+	var moveCount int = 0
+	leafTurn := ops.position.Turn()
+
+	for !ops.position.IsTerminated() {
+		moveCount++
+		moves = ops.position.GenerateMoves()
+
+		// Choose at random move
+		move = moves.Moves[ops.random.Int31()%int32(moves.Size)]
+		ops.position.MakeMove(move)
+	}
+
+	// If that's not a draw
+	if t := ops.position.Termination(); (t == uttt.TerminationCircleWon && leafTurn == uttt.CircleTurn) ||
+		(t == uttt.TerminationCrossWon && leafTurn == uttt.CrossTurn) {
+		result = 1.0
+		// We lost
+	} else if t != uttt.TerminationDraw {
+		result = 0.0
+	}
+
+	// Undo the moves
+	for range moveCount {
+		ops.position.Undo()
+	}
+
+	return result
+}
+
+// Sets the random number generator, called at the begining of the search
+func (ops *UtttOperations) SetRand(r *rand.Rand) {
+	ops.random = r
+}
+
+// It should return a deep copy of the ops object
+func (ops UtttOperations) Clone() *UtttOperations {
+	return &UtttOperations{
+		position: *ops.position.Clone(),
+		rootSide: ops.rootSide,
+	}
+}
+
+// Added for benchmarking purposes
+func (ops *UtttOperations) Position() *uttt.Position {
+	return &ops.position
+}
+
+func (ops *UtttOperations) SetPosition(pos uttt.Position) {
+	ops.position = pos
+	ops.rootSide = pos.Turn()
+}
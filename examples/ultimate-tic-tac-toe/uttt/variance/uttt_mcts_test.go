@@ -0,0 +1,39 @@
+package variance_uttt
+
+import (
+	"testing"
+
+	uttt "github.com/IlikeChooros/go-mcts/examples/ultimate-tic-tac-toe/uttt/core"
+)
+
+func TestMCTSSearch(t *testing.T) {
+	strategies := map[string]Strategy{
+		"UCB1-Tuned": NewUCB1Tuned(),
+		"UCB-V":      NewUCBV(),
+	}
+
+	for name, strategy := range strategies {
+		pos := uttt.NewPosition()
+		originalNotation := pos.Notation()
+
+		tree := NewUtttMCTS(*pos, strategy)
+		tree.Limits().SetCycles(10000).SetThreads(2)
+		tree.Search()
+
+		if tree.Root.Stats.N() == 0 {
+			t.Errorf("%s: root should have been visited during search", name)
+		}
+
+		// Position should be restored
+		if tree.Ops().Position().Notation() != originalNotation {
+			t.Errorf("%s: position not restored after search", name)
+		}
+
+		// Every backpropagated result is in [0, 1], so the squares can't exceed the sum
+		for _, child := range tree.Root.Children {
+			if child.Stats.SumSquares() > child.Stats.Q()+1e-6 {
+				t.Errorf("%s: sum of squares %f exceeds the sum %f", name, child.Stats.SumSquares(), child.Stats.Q())
+			}
+		}
+	}
+}
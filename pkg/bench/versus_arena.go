@@ -33,6 +33,13 @@ type ExtMCTS[T mcts.MoveLike, S mcts.NodeStatsLike[S], R mcts.GameResult, P Posi
 	Clone() ExtMCTS[T, S, R, P]
 }
 
+// Player able to search on the opponent's time (see mcts.MCTS.Ponder), its Search
+// should only wait for the search, if it's already running after a ponderhit
+type PonderingMCTS interface {
+	Ponder()
+	StopPondering()
+}
+
 type VersusArena[T mcts.MoveLike, P PositionLike[T, P], S1 mcts.NodeStatsLike[S1], R1 mcts.GameResult, S2 mcts.NodeStatsLike[S2], R2 mcts.GameResult] struct {
 	VersusArenaStats
 	Player1  ExtMCTS[T, S1, R1, P]
@@ -41,6 +48,8 @@ type VersusArena[T mcts.MoveLike, P PositionLike[T, P], S1 mcts.NodeStatsLike[S1
 	NThreads uint
 	Limits   *mcts.Limits
 	Position P
+	Ponder1  bool // Player1 searches on the opponent's time, must implement PonderingMCTS
+	Ponder2  bool // Player2 searches on the opponent's time, must implement PonderingMCTS
 	p1name   string
 	p2name   string
 	wg       sync.WaitGroup
@@ -70,6 +79,13 @@ func (va *VersusArena[T, P, S1, R1, S2, R2]) WithContext(ctx context.Context) *V
 	return va
 }
 
+// Enable pondering for either of the players
+func (va *VersusArena[T, P, S1, R1, S2, R2]) SetPondering(p1, p2 bool) *VersusArena[T, P, S1, R1, S2, R2] {
+	va.Ponder1 = p1
+	va.Ponder2 = p2
+	return va
+}
+
 func (va *VersusArena[T, P, S1, R1, S2, R2]) Setup(limits *mcts.Limits, nGames uint, nThreads uint) {
 	va.NGames = nGames
 	va.Limits = limits
@@ -90,6 +106,12 @@ func (va *VersusArena[T, P, S1, R1, S2, R2]) Wait() {
 func (va *VersusArena[T, P, S1, R1, S2, R2]) Start(p1name, p2name string, listener ListenerLike[T]) {
 	// Start equally distributed work between worker threads
 	va.finished.Store(false)
+	if _, ok := va.Player1.(PonderingMCTS); va.Ponder1 && !ok {
+		panic("[MCTS] VersusArena.Start: Player1 doesn't implement PonderingMCTS")
+	}
+	if _, ok := va.Player2.(PonderingMCTS); va.Ponder2 && !ok {
+		panic("[MCTS] VersusArena.Start: Player2 doesn't implement PonderingMCTS")
+	}
 	listener.OnStart()
 	nGames := va.NGames / va.NThreads
 	rest := uint(0)
@@ -153,11 +175,11 @@ WorkLoop:
 		var moves []T
 		if p1GoesFirst {
 			moves = playGameAndNotify(
-				va.ctx, p1, p2, gamePos, listener, id,
+				va.ctx, p1, p2, va.Ponder1, va.Ponder2, gamePos, listener, id,
 				nGames, gameIdx, &localStats, va.p1name, va.p2name, false)
 		} else {
 			moves = playGameAndNotify(
-				va.ctx, p2, p1, gamePos, listener, id,
+				va.ctx, p2, p1, va.Ponder2, va.Ponder1, gamePos, listener, id,
 				nGames, gameIdx, &localStats, va.p2name, va.p1name, true)
 		}
 
@@ -249,6 +271,7 @@ func playGameAndNotify[
 	ctx context.Context,
 	pl1 ExtMCTS[T, S1, R1, P],
 	pl2 ExtMCTS[T, S2, R2, P],
+	ponder1, ponder2 bool,
 	gamePos P,
 	listener ListenerLike[T],
 	workerID, totalGames, gameIdx int,
@@ -267,6 +290,10 @@ func playGameAndNotify[
 	pl1.SetPosition(gamePos.Clone())
 	pl2.SetPosition(gamePos.Clone())
 
+	// Pondering players are stopped, whenever the game ends
+	defer stopPondering(pl1, ponder1)
+	defer stopPondering(pl2, ponder2)
+
 	for !gamePos.IsTerminated() {
 		select {
 		case <-ctx.Done():
@@ -290,6 +317,7 @@ func playGameAndNotify[
 			return moves
 		}
 
+		startPondering(pl1, ponder1)
 		if !pl2.MakeMove(move) {
 			pl2.SetPosition(gamePos.Clone())
 		}
@@ -312,6 +340,11 @@ func playGameAndNotify[
 			))
 		}
 
+		if gamePos.IsTerminated() {
+			return moves
+		}
+
+		startPondering(pl2, ponder2)
 		if !pl1.MakeMove(move) {
 			pl1.SetPosition(gamePos.Clone())
 		}
@@ -320,6 +353,18 @@ func playGameAndNotify[
 	return moves
 }
 
+func startPondering[T mcts.MoveLike, S mcts.NodeStatsLike[S], R mcts.GameResult, P PositionLike[T, P]](pl ExtMCTS[T, S, R, P], ponder bool) {
+	if ponder {
+		pl.(PonderingMCTS).Ponder()
+	}
+}
+
+func stopPondering[T mcts.MoveLike, S mcts.NodeStatsLike[S], R mcts.GameResult, P PositionLike[T, P]](pl ExtMCTS[T, S, R, P], ponder bool) {
+	if ponder {
+		pl.(PonderingMCTS).StopPondering()
+	}
+}
+
 func undoMoves[T mcts.MoveLike, P PositionLike[T, P]](gamePos P, moves []T) {
 	for range moves {
 		gamePos.Undo()
@@ -130,7 +130,10 @@ func (dmcts *DummyMCTS) Reset() {
 }
 
 func (dmcts *DummyMCTS) Search() Move {
-	dmcts.MCTS.SearchMultiThreaded()
+	// After a ponderhit the search is already running
+	if !dmcts.MCTS.IsSearching() {
+		dmcts.MCTS.SearchMultiThreaded()
+	}
 	dmcts.MCTS.Synchronize()
 	return dmcts.MCTS.BestMove()
 }
@@ -173,3 +176,17 @@ func TestBasicListeners(t *testing.T) {
 
 	arena.Wait()
 }
+
+func TestPonderingArena(t *testing.T) {
+	t1 := NewDummyMCTS(mcts.MultithreadTreeParallel)
+	t2 := NewDummyMCTS(mcts.MultithreadTreeParallel)
+	arena := NewVersusArena(NewDummyPos(), t1, t2).SetPondering(true, false)
+
+	arena.Setup(mcts.DefaultLimits().SetCycles(500).SetThreads(2), 6, 3)
+	arena.Start("ponder", "test", &DefaultListener[Move]{})
+	arena.Wait()
+
+	if total := arena.Results().TotalGames; total != 6 {
+		t.Fatalf("Expected 6 games, got %d", total)
+	}
+}
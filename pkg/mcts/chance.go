@@ -0,0 +1,149 @@
+package mcts
+
+import "math/rand"
+
+// Chance nodes, for stochastic games (dice rolls, random card draws)
+// Reference: https://dke.maastrichtuniversity.nl/m.winands/documents/Lanctot2013MCTSChanceNodes.pdf
+//
+// A chance node is a position where the next move is a random event, instead of a player's decision.
+// Its children are the possible outcomes, each with its probability (NodeBase.Prob).
+// The selection samples the outcome in proportion to its probability, and the results
+// aren't flipped between the chance node and its outcomes, so both hold the values from
+// the perspective of the player that moved into the chance node. The value of
+// a chance node is the expectation over its outcomes.
+
+// Game operations of the game with random events, chance positions are detected
+// right after ExpandNode (with the position set to the expanded node)
+type ChanceGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	GameOperations[T, S, R, O]
+	// Whether the current position is a chance event. ExpandNode of such position
+	// must create the outcomes, with their (unnormalized) probabilities set in NodeBase.Prob,
+	// if none are given, the outcomes are equally likely.
+	// Rollout of the chance position is from the perspective of the player to move after the event.
+	IsChance() bool
+}
+
+// Creates a new outcome of the chance node, with the probability 'prob'
+func NewOutcomeNode[T MoveLike, S NodeStatsLike[S]](parent *NodeBase[T, S], move T, terminated bool, prob float64, defaultStats S) *NodeBase[T, S] {
+	node := NewBaseNode(parent, move, terminated, defaultStats)
+	node.Prob = float32(prob)
+	return node
+}
+
+// Marks freshly expanded 'node' as a chance node (if it's one), and normalizes the probabilities of its outcomes
+func (mcts *MCTS[T, S, R, O, A]) setChance(node *NodeBase[T, S], ops O) {
+	chanceOps, ok := GameOperations[T, S, R, O](ops).(ChanceGameOperations[T, S, R, O])
+	if !ok || !chanceOps.IsChance() {
+		return
+	}
+
+	probs := make([]float64, len(node.Children))
+	for i := range node.Children {
+		probs[i] = float64(node.Children[i].Prob)
+	}
+	normalizePriors(probs)
+
+	for i := range node.Children {
+		node.Children[i].Prob = float32(probs[i])
+	}
+	node.updateFlags(ChanceMask, 0)
+}
+
+// Samples the outcome of the chance node, in proportion to the probabilities
+func sampleOutcome[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S], threadRand *rand.Rand) *NodeBase[T, S] {
+	r := threadRand.Float32()
+	for i := range node.Children {
+		r -= node.Children[i].Prob
+		if r < 0 {
+			return &node.Children[i]
+		}
+	}
+	// Rounding errors
+	return &node.Children[len(node.Children)-1]
+}
+
+// Chooses the child of the 'node', sampling the outcome for chance nodes
+// and using the strategy otherwise ('isOps' is nil, unless the game has hidden information).
+// May return nil, if no child is legal in the current determinization.
+func (mcts *MCTS[T, S, R, O, A]) selectChild(node, root *NodeBase[T, S], isOps ISGameOperations[T, S, R, O], ctx SelectionContext) *NodeBase[T, S] {
+	if node.Chance() {
+		return sampleOutcome(node, ctx.Rand)
+	}
+	if node == root {
+		if child := mcts.noisyChild(root, isOps, ctx.Rand); child != nil {
+			return child
+		}
+	}
+	if isOps != nil {
+		return any(mcts.strategy).(DeterminizedStrategy[T, S, R, O]).SelectLegal(node, root, isOps)
+	}
+	if strategy, ok := any(mcts.strategy).(ContextStrategy[T, S]); ok {
+		return strategy.SelectContext(node, root, ctx)
+	}
+	return mcts.strategy.Select(node, root)
+}
+
+// Random child of the freshly expanded 'node', the same rules as in selectChild apply
+func randomChild[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](
+	node *NodeBase[T, S], isOps ISGameOperations[T, S, R, O], threadRand *rand.Rand) *NodeBase[T, S] {
+	if node.Chance() {
+		return sampleOutcome(node, threadRand)
+	}
+	children := node.VisibleChildren()
+	if isOps == nil {
+		return &children[threadRand.Int31()%int32(len(children))]
+	}
+
+	// Reservoir sampling among the legal children
+	var child *NodeBase[T, S]
+	legal := int32(0)
+	for i := range children {
+		if isOps.IsLegal(children[i].Move) {
+			// Every legal child is available, same as in the selection (see ISUCB1)
+			if stats, ok := any(children[i].Stats).(interface{ AddAvailability(int32) }); ok {
+				stats.AddAvailability(1)
+			}
+			legal++
+			if threadRand.Int31n(legal) == 0 {
+				child = &children[i]
+			}
+		}
+	}
+	return child
+}
+
+// The most likely outcome of the chance node, the one with more visits on ties
+func likelyOutcome[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S]) *NodeBase[T, S] {
+	best := &node.Children[0]
+	for i := 1; i < len(node.Children); i++ {
+		child := &node.Children[i]
+		if child.Prob > best.Prob || (child.Prob == best.Prob && child.Stats.RealVisits() > best.Stats.RealVisits()) {
+			best = child
+		}
+	}
+	return best
+}
+
+// Value of the node from the perspective of the player that moved into it,
+// for chance nodes it's the expectation over the visited outcomes
+func nodeValue[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S]) float64 {
+	if node.Chance() && node.Expanded() {
+		value, weight := 0.0, 0.0
+		for i := range node.Children {
+			child := &node.Children[i]
+			if child.Stats.RealVisits() > 0 {
+				value += float64(child.Prob) * nodeValue(child)
+				weight += float64(child.Prob)
+			}
+		}
+
+		if weight > 0 {
+			return value / weight
+		}
+	}
+
+	if node.Stats.N() == 0 {
+		return 0
+	}
+	return float64(node.Stats.Q()) / float64(node.Stats.N())
+}
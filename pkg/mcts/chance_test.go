@@ -0,0 +1,162 @@
+package mcts
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// The player either takes a safe reward, or gambles: wins with probability 'winProb',
+// loses otherwise. The gamble leads to a chance node with 2 outcomes.
+const (
+	chanceSafe   Move = 0
+	chanceGamble Move = 1
+	chanceWin    Move = 0
+	chanceLoss   Move = 1
+
+	chanceSafeReward = 0.6
+)
+
+type ChanceOps struct {
+	winProb float64
+	history []Move
+	rand    *rand.Rand
+}
+
+func (o *ChanceOps) Reset()          {}
+func (o *ChanceOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *ChanceOps) BackTraverse() {
+	if len(o.history) > 0 {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *ChanceOps) IsChance() bool {
+	return len(o.history) == 1 && o.history[0] == chanceGamble
+}
+
+func (o *ChanceOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	switch {
+	case len(o.history) == 0:
+		parent.Children = []NodeBase[Move, *NodeStats]{
+			*NewBaseNode(parent, chanceSafe, true, &NodeStats{}),
+			*NewBaseNode(parent, chanceGamble, false, &NodeStats{}),
+		}
+	case o.IsChance():
+		parent.Children = []NodeBase[Move, *NodeStats]{
+			*NewOutcomeNode(parent, chanceWin, true, o.winProb, &NodeStats{}),
+			*NewOutcomeNode(parent, chanceLoss, true, 1-o.winProb, &NodeStats{}),
+		}
+	default:
+		return 0
+	}
+	return uint32(len(parent.Children))
+}
+
+// From the perspective of the enemy (side to move after the root player's move)
+func (o *ChanceOps) Rollout() Result {
+	if o.history[0] == chanceSafe {
+		return 1 - chanceSafeReward
+	}
+
+	won := o.rand.Float64() < o.winProb
+	if len(o.history) == 2 {
+		won = o.history[1] == chanceWin
+	}
+
+	if won {
+		return 0
+	}
+	return 1
+}
+
+func (o *ChanceOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *ChanceOps) Clone() *ChanceOps {
+	return &ChanceOps{winProb: o.winProb, history: append([]Move(nil), o.history...)}
+}
+
+func newChanceMCTS(winProb float64) *MCTS[Move, *NodeStats, Result, *ChanceOps, *UCB1[Move, *NodeStats, Result, *ChanceOps]] {
+	// High exploration, so that the worse move still gets enough visits to sample both outcomes
+	return NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *ChanceOps](2),
+		&ChanceOps{winProb: winProb},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+}
+
+func TestChanceNodes(t *testing.T) {
+	cases := []struct {
+		winProb float64
+		best    Move
+	}{
+		{0.3, chanceSafe},
+		{0.8, chanceGamble},
+	}
+
+	for _, c := range cases {
+		tree := newChanceMCTS(c.winProb)
+		tree.SetLimits(DefaultLimits().SetCycles(20000).SetThreads(2))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		gamble := &tree.Root.Children[chanceGamble]
+		if !gamble.Chance() || tree.Root.Chance() {
+			t.Fatal("Expected only the gamble node to be a chance node")
+		}
+
+		// Outcomes are sampled in proportion to their probabilities
+		win, loss := gamble.Children[chanceWin].Stats.N(), gamble.Children[chanceLoss].Stats.N()
+		tolerance := 4 * math.Sqrt(c.winProb*(1-c.winProb)/float64(win+loss))
+		if ratio := float64(win) / float64(win+loss); math.Abs(ratio-c.winProb) > tolerance {
+			t.Fatalf("Expected outcome ratio around %.2f, got %.2f (%d/%d)", c.winProb, ratio, win, loss)
+		}
+
+		// Chance node is valued as the expectation
+		if value := nodeValue(gamble); math.Abs(value-c.winProb) > 1e-3 {
+			t.Fatalf("Expected the gamble value %.2f, got %.4f", c.winProb, value)
+		}
+
+		if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != c.best {
+			t.Fatalf("p=%.1f: expected best move %d, got %d", c.winProb, c.best, best.Move)
+		}
+
+		likely := chanceWin
+		if c.winProb < 0.5 {
+			likely = chanceLoss
+		}
+		if best := tree.BestChild(gamble, BestChildMostVisits); best.Move != likely {
+			t.Fatalf("Expected the most likely outcome %d, got %d", likely, best.Move)
+		}
+
+		expected := max(chanceSafeReward, c.winProb)
+		if score := float64(tree.RootScore()); math.Abs(score-expected) > 0.05 {
+			t.Fatalf("Expected root score around %.2f, got %.2f", expected, score)
+		}
+	}
+}
+
+func TestChanceNodesSaveLoad(t *testing.T) {
+	tree := newChanceMCTS(0.3)
+	tree.SetLimits(DefaultLimits().SetCycles(1000).SetThreads(1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	buf := &bytes.Buffer{}
+	if err := tree.Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := newChanceMCTS(0.3)
+	if err := loaded.Load(buf, BinaryMoveCodec[Move]{}, &NodeStats{}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !deepCompare(tree.Root, loaded.Root) {
+		t.Fatal("Loaded tree differs from the saved one")
+	}
+}
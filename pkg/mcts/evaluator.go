@@ -0,0 +1,193 @@
+package mcts
+
+import (
+	"sync"
+	"time"
+)
+
+// Leaf evaluator, replaces the GameOperations.Rollout in the search, so that
+// static evaluation functions (or value models) can be used instead of random playouts.
+// When an evaluator is set, Rollout is never called by the search.
+type Evaluator[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] interface {
+	// Value estimate of the position of 'ops' (which is set to the 'leaf' node),
+	// with the same perspective as Rollout: 1 - win for the side to move in the leaf, 0 - loss.
+	// 'leaf' may be terminal, the evaluator should then return the actual game result.
+	// Called concurrently by the search threads, each with its own 'ops'.
+	Evaluate(ops O, leaf *NodeBase[T, S]) R
+}
+
+// Evaluator that also gives move priors (see PUCT), the priors of the evaluator
+// take precedence over the PriorGameOperations
+type PriorEvaluator[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] interface {
+	Evaluator[T, S, R, O]
+	// Fill 'priors' with the (unnormalized) weights of the parent's children,
+	// 'ops' is set to the parent's position
+	Priors(ops O, parent *NodeBase[T, S], priors []float64)
+}
+
+// Wraps a function as an Evaluator
+type EvaluatorFunc[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] func(ops O, leaf *NodeBase[T, S]) R
+
+func (f EvaluatorFunc[T, S, R, O]) Evaluate(ops O, leaf *NodeBase[T, S]) R {
+	return f(ops, leaf)
+}
+
+// Set the leaf evaluator, nil restores the rollouts. Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetEvaluator(evaluator Evaluator[T, S, R, O]) {
+	mcts.evaluator = evaluator
+}
+
+// Returns the leaf evaluator, nil if the rollouts are used
+func (mcts *MCTS[T, S, R, O, A]) Evaluator() Evaluator[T, S, R, O] {
+	return mcts.evaluator
+}
+
+// Result of the 'leaf' node, exact if the leaf is proven by the solver,
+// otherwise the evaluation
+func (mcts *MCTS[T, S, R, O, A]) leafResult(ops O, leaf *NodeBase[T, S]) R {
+	if mcts.solver && !leaf.Terminal() {
+		if result, ok := mcts.provenResult(leaf); ok {
+			return result
+		}
+	}
+	return mcts.evaluate(ops, leaf)
+}
+
+// Result of the 'leaf' node, either from the evaluator or the rollout
+func (mcts *MCTS[T, S, R, O, A]) evaluate(ops O, leaf *NodeBase[T, S]) R {
+	if mcts.evaluator != nil {
+		return mcts.evaluator.Evaluate(ops, leaf)
+	}
+	return ops.Rollout()
+}
+
+// Scores the whole batch at once, results[i] is the result of leaves[i] with position ops[i].
+// Positions stay unchanged until the function returns, since every search thread
+// waits for its result.
+type BatchEvaluateFunc[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] func(ops []O, leaves []*NodeBase[T, S], results []R)
+
+type batchRequest[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	ops    O
+	leaf   *NodeBase[T, S]
+	result R
+	done   chan struct{}
+}
+
+// Evaluator collecting leaves from multiple search threads, and scoring them with a single
+// BatchEvaluateFunc call. The batch is evaluated when it's full (BatchSize leaves), or when
+// MaxWait passed since the first leaf was queued. The thread that completes the batch
+// (or the timer) runs the evaluation, other threads wait for their results.
+//
+// BatchSize should not be greater than the number of search threads (Limits.NThreads),
+// otherwise every batch waits for the MaxWait timeout.
+type BatchedEvaluator[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	evaluate  BatchEvaluateFunc[T, S, R, O]
+	batchSize int
+	maxWait   time.Duration
+	mx        sync.Mutex
+	pending   []*batchRequest[T, S, R, O]
+	timer     *time.Timer
+	// Incremented with every taken batch, the timer flushes only the batch it was started for
+	generation uint64
+}
+
+func NewBatchedEvaluator[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](
+	evaluate BatchEvaluateFunc[T, S, R, O],
+	batchSize int,
+	maxWait time.Duration,
+) *BatchedEvaluator[T, S, R, O] {
+	if evaluate == nil {
+		panic("[MCTS] NewBatchedEvaluator: evaluate function cannot be nil")
+	}
+
+	return &BatchedEvaluator[T, S, R, O]{
+		evaluate:  evaluate,
+		batchSize: max(1, batchSize),
+		maxWait:   maxWait,
+	}
+}
+
+func (b *BatchedEvaluator[T, S, R, O]) BatchSize() int {
+	return b.batchSize
+}
+
+func (b *BatchedEvaluator[T, S, R, O]) MaxWait() time.Duration {
+	return b.maxWait
+}
+
+// Queues the leaf and waits for the batch to be evaluated
+func (b *BatchedEvaluator[T, S, R, O]) Evaluate(ops O, leaf *NodeBase[T, S]) R {
+	req := &batchRequest[T, S, R, O]{ops: ops, leaf: leaf, done: make(chan struct{})}
+
+	b.mx.Lock()
+	b.pending = append(b.pending, req)
+	if len(b.pending) >= b.batchSize {
+		batch := b.take()
+		b.mx.Unlock()
+		b.run(batch)
+	} else {
+		if len(b.pending) == 1 {
+			generation := b.generation
+			b.timer = time.AfterFunc(b.maxWait, func() { b.flushGeneration(generation) })
+		}
+		b.mx.Unlock()
+	}
+
+	<-req.done
+	return req.result
+}
+
+// Evaluates the queued leaves right away
+func (b *BatchedEvaluator[T, S, R, O]) Flush() {
+	b.mx.Lock()
+	batch := b.take()
+	b.mx.Unlock()
+	b.run(batch)
+}
+
+// Evaluates the queued leaves, if they are still the batch of given generation. Stopping
+// the timer doesn't stop its callback if it already fired, so it could cut the next batch short.
+func (b *BatchedEvaluator[T, S, R, O]) flushGeneration(generation uint64) {
+	b.mx.Lock()
+	if b.generation != generation {
+		b.mx.Unlock()
+		return
+	}
+	batch := b.take()
+	b.mx.Unlock()
+	b.run(batch)
+}
+
+// Removes pending requests from the queue, must be called with the lock held
+func (b *BatchedEvaluator[T, S, R, O]) take() []*batchRequest[T, S, R, O] {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.generation++
+
+	batch := b.pending
+	b.pending = nil
+	return batch
+}
+
+func (b *BatchedEvaluator[T, S, R, O]) run(batch []*batchRequest[T, S, R, O]) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ops := make([]O, len(batch))
+	leaves := make([]*NodeBase[T, S], len(batch))
+	results := make([]R, len(batch))
+	for i, req := range batch {
+		ops[i] = req.ops
+		leaves[i] = req.leaf
+	}
+
+	b.evaluate(ops, leaves, results)
+
+	for i, req := range batch {
+		req.result = results[i]
+		close(req.done)
+	}
+}
@@ -0,0 +1,113 @@
+package mcts
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvaluatorReplacesRollout(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	calls := atomic.Int32{}
+
+	// Prefer the move 0 from the root perspective
+	tree.SetEvaluator(EvaluatorFunc[Move, *NodeStats, Result, *DummyOps](
+		func(ops *DummyOps, leaf *NodeBase[Move, *NodeStats]) Result {
+			calls.Add(1)
+			if ops.depth == 1 && leaf.Move == 0 {
+				return 0.0 // loss for the side to move in the leaf
+			}
+			return 0.5
+		}))
+
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if int(calls.Load()) != tree.Cycles() {
+		t.Fatalf("Evaluator called %d times, expected %d", calls.Load(), tree.Cycles())
+	}
+
+	if tree.BestMove() != 0 {
+		t.Fatalf("Expected best move 0, got %d", tree.BestMove())
+	}
+}
+
+func TestBatchedEvaluator(t *testing.T) {
+	const threads = 4
+	calls, evaluated, maxBatch := atomic.Int32{}, atomic.Int32{}, atomic.Int32{}
+
+	evaluator := NewBatchedEvaluator(func(ops []*DummyOps, leaves []*NodeBase[Move, *NodeStats], results []Result) {
+		calls.Add(1)
+		evaluated.Add(int32(len(leaves)))
+		if n := int32(len(leaves)); n > maxBatch.Load() {
+			maxBatch.Store(n)
+		}
+
+		for i := range results {
+			results[i] = 0.5
+		}
+	}, threads, 5*time.Millisecond)
+
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	tree.SetEvaluator(evaluator)
+	tree.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if int(evaluated.Load()) != tree.Cycles() {
+		t.Fatalf("Evaluated %d leaves, expected %d", evaluated.Load(), tree.Cycles())
+	}
+
+	if maxBatch.Load() < 2 || maxBatch.Load() > threads {
+		t.Fatalf("Unexpected max batch size %d", maxBatch.Load())
+	}
+
+	t.Logf("cycles %d, batches %d, max batch %d", tree.Cycles(), calls.Load(), maxBatch.Load())
+}
+
+func TestBatchedEvaluatorStaleTimer(t *testing.T) {
+	evaluated := atomic.Int32{}
+	evaluator := NewBatchedEvaluator(func(ops []*DummyOps, leaves []*NodeBase[Move, *NodeStats], results []Result) {
+		evaluated.Add(int32(len(leaves)))
+	}, 2, time.Hour)
+
+	// Waits until the leaf is queued, and returns the generation its timer was started with
+	queue := func(done chan struct{}) uint64 {
+		go func() {
+			evaluator.Evaluate(&DummyOps{}, &NodeBase[Move, *NodeStats]{})
+			close(done)
+		}()
+		for {
+			evaluator.mx.Lock()
+			queued, generation := len(evaluator.pending), evaluator.generation
+			evaluator.mx.Unlock()
+			if queued == 1 {
+				return generation
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	first := make(chan struct{})
+	stale := queue(first)
+	evaluator.Flush()
+	<-first
+
+	// Timer of the first batch fired after it was flushed, the next batch must keep waiting
+	second := make(chan struct{})
+	queue(second)
+	evaluator.flushGeneration(stale)
+
+	select {
+	case <-second:
+		t.Fatal("Stale timer evaluated the next batch")
+	default:
+	}
+
+	evaluator.Flush()
+	<-second
+	if evaluated.Load() != 2 {
+		t.Fatalf("Expected 2 evaluated leaves, got %d", evaluated.Load())
+	}
+}
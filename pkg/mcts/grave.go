@@ -0,0 +1,82 @@
+package mcts
+
+// Generalized Rapid Action Value Estimation (GRAVE)
+// Reference: Cazenave, Generalized Rapid Action Value Estimation (IJCAI 2015)
+//
+// RAVE blends the value of a child with the AMAF statistics of its move among the parent's children,
+// which are noisy deep in the tree, where the nodes have few playouts. GRAVE uses the AMAF statistics
+// of the same move in the closest ancestor with more than Ref playouts instead (the parent itself, if it
+// has enough of them). The statistics are gathered by the RAVE backpropagation, in every node.
+//
+// Only the ancestors with the same player to move as the parent are considered (an even number of moves
+// above it, the chance nodes are skipped), since the AMAF statistics hold the moves of that player.
+// With Ref = 0, GRAVE is the same as RAVE.
+
+type GRAVE[T MoveLike, S RaveStatsLike[S], R RaveGameResult[T], O GameOperations[T, S, R, O]] struct {
+	RAVE[T, S, R, O]
+	// Minimum playouts of the node whose AMAF statistics are used
+	Ref int32
+}
+
+func NewGRAVE[T MoveLike, S RaveStatsLike[S], R RaveGameResult[T], O GameOperations[T, S, R, O]]() *GRAVE[T, S, R, O] {
+	return &GRAVE[T, S, R, O]{
+		RAVE: *NewRAVE[T, S, R, O](),
+		Ref:  50, // value suggested by the paper
+	}
+}
+
+func (g *GRAVE[T, S, R, O]) SetRef(ref int32) *GRAVE[T, S, R, O] {
+	g.Ref = max(0, ref)
+	return g
+}
+
+func (g *GRAVE[T, S, R, O]) SetExplorationParam(c float64) *GRAVE[T, S, R, O] {
+	g.RAVE.SetExplorationParam(c)
+	return g
+}
+
+// See RAVE.SetBetaFunction
+func (g *GRAVE[T, S, R, O]) SetBetaFunction(f RaveBetaFnType) *GRAVE[T, S, R, O] {
+	g.RAVE.SetBetaFunction(f)
+	return g
+}
+
+func (g GRAVE[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	return g.selectAMAF(parent, referenceNode(parent, g.Ref))
+}
+
+// Closest ancestor of the 'node' (or the node itself) with the same player to move and more than 'ref'
+// playouts, the topmost one if none has enough of them
+func referenceNode[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S], ref int32) *NodeBase[T, S] {
+	reference := node
+	moves := 0
+	for ancestor := node; ; {
+		if moves%2 == 0 && !ancestor.Chance() {
+			reference = ancestor
+			if ancestor.Stats.RealVisits() > ref {
+				break
+			}
+		}
+
+		if ancestor.Parent == nil {
+			break
+		}
+		if !ancestor.Parent.Chance() {
+			moves++
+		}
+		ancestor = ancestor.Parent
+	}
+	return reference
+}
+
+// Child with the given move, searching from the 'hint' index (children of the nodes close in the tree are
+// usually generated in the same order), returns the next hint. Nil if there is no such child.
+func findMove[T MoveLike, S NodeStatsLike[S]](children []NodeBase[T, S], move T, hint int) (*NodeBase[T, S], int) {
+	for i := range children {
+		index := (hint + i) % len(children)
+		if children[index].Move == move {
+			return &children[index], index + 1
+		}
+	}
+	return nil, hint
+}
@@ -0,0 +1,69 @@
+package mcts
+
+import "testing"
+
+// Chain of nodes from the root, with the given visits, the ones in 'chance' are chance nodes
+func newChain(visits []int32, chance map[int]bool) []*NodeBase[Move, *NodeStats] {
+	nodes := make([]*NodeBase[Move, *NodeStats], len(visits))
+	var parent *NodeBase[Move, *NodeStats]
+	for i, n := range visits {
+		node := NewBaseNode(parent, Move(i), false, &NodeStats{})
+		node.Stats.SetVvl(n, 0)
+		if chance[i] {
+			node.updateFlags(ChanceMask, 0)
+		}
+		nodes[i], parent = node, node
+	}
+	return nodes
+}
+
+func TestGraveReferenceNode(t *testing.T) {
+	nodes := newChain([]int32{1000, 400, 100, 30, 10}, nil)
+	leaf := nodes[4]
+
+	// Same player to move: 2 and 4 moves above the leaf
+	if ref := referenceNode(leaf, 50); ref != nodes[2] {
+		t.Fatalf("Expected the grandparent, got the node at depth %d", ref.Move)
+	}
+	if ref := referenceNode(leaf, 200); ref != nodes[0] {
+		t.Fatalf("Expected the root, got the node at depth %d", ref.Move)
+	}
+	if ref := referenceNode(leaf, 5); ref != leaf {
+		t.Fatalf("Expected the node itself, got the node at depth %d", ref.Move)
+	}
+
+	// Topmost same player node, if none has enough playouts
+	if ref := referenceNode(nodes[3], 1<<20); ref != nodes[1] {
+		t.Fatalf("Expected the root's child, got the node at depth %d", ref.Move)
+	}
+
+	// Chance node and its outcome share the player
+	nodes = newChain([]int32{1000, 400, 100, 30}, map[int]bool{1: true})
+	if ref := referenceNode(nodes[3], 50); ref != nodes[0] {
+		t.Fatalf("Expected the root (2 moves above), got the node at depth %d", ref.Move)
+	}
+	if ref := referenceNode(nodes[2], 50); ref != nodes[2] {
+		t.Fatalf("Expected the node itself, got the node at depth %d", ref.Move)
+	}
+}
+
+func TestGraveFindMove(t *testing.T) {
+	children := make([]NodeBase[Move, *NodeStats], 5)
+	for i := range children {
+		children[i] = *NewBaseNode(nil, Move(i*2), false, &NodeStats{})
+	}
+
+	child, hint := findMove(children, 6, 0)
+	if child != &children[3] || hint != 4 {
+		t.Fatalf("Expected the 4th child and hint 4, got %v, %d", child, hint)
+	}
+	if child, _ = findMove(children, 2, hint); child != &children[1] {
+		t.Fatalf("Expected the search to wrap around, got %v", child)
+	}
+	if child, hint = findMove(children, 3, 2); child != nil || hint != 2 {
+		t.Fatalf("Expected no child and the same hint, got %v, %d", child, hint)
+	}
+	if child, _ = findMove[Move, *NodeStats](nil, 3, 0); child != nil {
+		t.Fatal("Expected no child without children")
+	}
+}
@@ -0,0 +1,164 @@
+package mcts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// Information Set MCTS (single observer), for games with hidden information (card games)
+// Reference: https://eprints.whiterose.ac.uk/75048/1/CowlingPowleyWhitehouse2012.pdf
+//
+// The tree is built over information sets, instead of the concrete states: every iteration
+// samples the hidden information (determinization), and only the children legal in that
+// determinization can be selected. Since a child isn't always available, the exploration term
+// uses the number of times it was available, instead of the parent's visits.
+// The solver (see MCTS.SetSolver) isn't sound with hidden information.
+
+// Game operations of the game with hidden information, requires a DeterminizedStrategy (see ISUCB1).
+// ExpandNode must create the children for every move possible in the information set
+// (not only the ones legal in the current determinization).
+type ISGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	RandGameOperations[T, S, R, O]
+	// Samples the hidden information (opponents' cards, for example), consistent with the observations
+	// of the player to move in the root, using the generator given by SetRand.
+	// Called at the start of every iteration, with the position set to the root.
+	Determinize()
+	// Whether the move is legal in the current determinization
+	IsLegal(T) bool
+}
+
+// Strategy selecting only among the children legal in the current determinization,
+// required by the ISGameOperations
+type DeterminizedStrategy[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] interface {
+	StrategyLike[T, S, R, O]
+	// Same as Select, but skips the children for which ops.IsLegal(child.Move) is false,
+	// returns nil if there is no legal child
+	SelectLegal(parent, root *NodeBase[T, S], ops ISGameOperations[T, S, R, O]) *NodeBase[T, S]
+}
+
+type ISStatsLike[S any] interface {
+	NodeStatsLike[S]
+
+	// Number of times the node was available (legal) when its parent was selected
+	Availability() int32
+	AddAvailability(int32)
+}
+
+// Node statistics with the availability count
+type ISStats struct {
+	NodeStats
+
+	avail int32
+}
+
+func DefaultISStats() *ISStats {
+	return &ISStats{}
+}
+
+func (s *ISStats) Clone() *ISStats {
+	return &ISStats{
+		NodeStats: NodeStats{
+			q:           atomic.LoadUint64(&s.q),
+			n:           atomic.LoadInt32(&s.n),
+			virtualLoss: atomic.LoadInt32(&s.virtualLoss),
+		},
+		avail: atomic.LoadInt32(&s.avail),
+	}
+}
+
+func (s *ISStats) Availability() int32 {
+	return atomic.LoadInt32(&s.avail)
+}
+
+func (s *ISStats) AddAvailability(n int32) {
+	atomic.AddInt32(&s.avail, n)
+}
+
+// Encodes the node counters and the availability, implements encoding.BinaryMarshaler
+func (s *ISStats) MarshalBinary() ([]byte, error) {
+	buf := s.NodeStats.appendBinary(make([]byte, 0, nodeStatsBinarySize+4))
+	return binary.LittleEndian.AppendUint32(buf, uint32(s.Availability())), nil
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (s *ISStats) UnmarshalBinary(data []byte) error {
+	if len(data) < nodeStatsBinarySize+4 {
+		return fmt.Errorf("[MCTS] ISStats: expected %d bytes, got %d", nodeStatsBinarySize+4, len(data))
+	}
+
+	if err := s.NodeStats.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.avail, int32(binary.LittleEndian.Uint32(data[nodeStatsBinarySize:])))
+	return nil
+}
+
+// UCB1 with availability counts, for 2 player zero-sum games with hidden information
+type ISUCB1[T MoveLike, S ISStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	DefaultBackprop[T, S, R, O]
+	ExplorationParam float64
+}
+
+func NewISUCB1[T MoveLike, S ISStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](explorationParam float64) *ISUCB1[T, S, R, O] {
+	return &ISUCB1[T, S, R, O]{ExplorationParam: explorationParam}
+}
+
+func (u *ISUCB1[T, S, R, O]) SetExplorationParam(c float64) *ISUCB1[T, S, R, O] {
+	u.ExplorationParam = max(0, c)
+	return u
+}
+
+// Selection without the determinization, every child is treated as legal
+func (u *ISUCB1[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	return u.selectLegal(parent, nil)
+}
+
+func (u *ISUCB1[T, S, R, O]) SelectLegal(parent, root *NodeBase[T, S], ops ISGameOperations[T, S, R, O]) *NodeBase[T, S] {
+	return u.selectLegal(parent, ops)
+}
+
+func (u *ISUCB1[T, S, R, O]) selectLegal(parent *NodeBase[T, S], ops ISGameOperations[T, S, R, O]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := math.Inf(-1)
+	var bestChild, unvisited, child *NodeBase[T, S]
+	var visits, vl int32
+
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
+		child = &children[i]
+		if ops != nil && !ops.IsLegal(child.Move) {
+			continue
+		}
+
+		// Every legal child is available, even if it isn't chosen
+		child.Stats.AddAvailability(1)
+		visits, vl = child.Stats.GetVvl()
+
+		// Pick the first unvisited one
+		if visits-vl == 0 {
+			if unvisited == nil {
+				unvisited = child
+			}
+			continue
+		}
+
+		// IS-UCB1: wins/visits + C * sqrt(ln(availability)/visits)
+		ucb1 := float64(child.Stats.Q())/float64(visits) +
+			u.ExplorationParam*math.Sqrt(math.Log(float64(child.Stats.Availability()))/float64(visits))
+
+		if ucb1 > best {
+			best = ucb1
+			bestChild = child
+		}
+	}
+
+	if unvisited != nil {
+		return unvisited
+	}
+	return bestChild
+}
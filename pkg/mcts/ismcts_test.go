@@ -0,0 +1,144 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// The root player either passes (draw) or bets. After the bet, the enemy must play
+// their hidden card: the root player wins against the low card, and loses against the high one.
+const (
+	isPass Move = 0
+	isBet  Move = 1
+	isLow  Move = 0
+	isHigh Move = 1
+)
+
+type HiddenCardOps struct {
+	highProb float64
+	card     Move
+	history  []Move
+	rand     *rand.Rand
+}
+
+func (o *HiddenCardOps) Reset()          {}
+func (o *HiddenCardOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *HiddenCardOps) BackTraverse() {
+	if len(o.history) > 0 {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *HiddenCardOps) Determinize() {
+	o.card = isLow
+	if o.rand.Float64() < o.highProb {
+		o.card = isHigh
+	}
+}
+
+func (o *HiddenCardOps) IsLegal(m Move) bool {
+	// Only the enemy's move depends on the hidden card
+	return len(o.history) == 0 || m == o.card
+}
+
+func (o *HiddenCardOps) ExpandNode(parent *NodeBase[Move, *ISStats]) uint32 {
+	switch {
+	case len(o.history) == 0:
+		parent.Children = []NodeBase[Move, *ISStats]{
+			*NewBaseNode(parent, isPass, true, &ISStats{}),
+			*NewBaseNode(parent, isBet, false, &ISStats{}),
+		}
+	case len(o.history) == 1 && o.history[0] == isBet:
+		// Every card the enemy might hold
+		parent.Children = []NodeBase[Move, *ISStats]{
+			*NewBaseNode(parent, isLow, true, &ISStats{}),
+			*NewBaseNode(parent, isHigh, true, &ISStats{}),
+		}
+	default:
+		return 0
+	}
+	return uint32(len(parent.Children))
+}
+
+// From the perspective of the side to move
+func (o *HiddenCardOps) Rollout() Result {
+	switch {
+	case o.history[0] == isPass:
+		return 0.5
+	case len(o.history) == 1:
+		// Enemy to move, wins with the high card
+		if o.card == isHigh {
+			return 1
+		}
+		return 0
+	case o.history[1] == isHigh:
+		return 0
+	}
+	return 1
+}
+
+func (o *HiddenCardOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *HiddenCardOps) Clone() *HiddenCardOps {
+	return &HiddenCardOps{highProb: o.highProb, card: o.card, history: append([]Move(nil), o.history...)}
+}
+
+func TestISMCTS(t *testing.T) {
+	cases := []struct {
+		highProb float64
+		best     Move
+	}{
+		{0.7, isPass},
+		{0.2, isBet},
+	}
+
+	for _, c := range cases {
+		tree := NewMTCS(
+			NewISUCB1[Move, *ISStats, Result, *HiddenCardOps](0.4),
+			&HiddenCardOps{highProb: c.highProb},
+			MultithreadTreeParallel,
+			&ISStats{},
+		)
+		tree.SetLimits(DefaultLimits().SetCycles(20000).SetThreads(2))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != c.best {
+			t.Fatalf("high=%.1f: expected best move %d, got %d", c.highProb, c.best, best.Move)
+		}
+
+		// Enemy's cards are visited as often as they are dealt
+		bet := &tree.Root.Children[isBet]
+		low, high := &bet.Children[isLow], &bet.Children[isHigh]
+		total := float64(low.Stats.N() + high.Stats.N())
+		tolerance := 4 * math.Sqrt(c.highProb*(1-c.highProb)/total)
+		if ratio := float64(high.Stats.N()) / total; math.Abs(ratio-c.highProb) > tolerance {
+			t.Fatalf("Expected high card ratio around %.2f, got %.2f", c.highProb, ratio)
+		}
+
+		// Children are available whenever they are legal, so at least every time they were visited
+		for _, child := range []*NodeBase[Move, *ISStats]{low, high} {
+			if avail := child.Stats.Availability(); avail < child.Stats.RealVisits() {
+				t.Fatalf("Unexpected availability %d of the card with %d visits", avail, child.Stats.RealVisits())
+			}
+		}
+	}
+}
+
+func TestISMCTSRequiresDeterminizedStrategy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic, when the strategy doesn't implement DeterminizedStrategy")
+		}
+	}()
+
+	NewMTCS(
+		NewUCB1[Move, *ISStats, Result, *HiddenCardOps](0.4),
+		&HiddenCardOps{},
+		MultithreadTreeParallel,
+		&ISStats{},
+	)
+}
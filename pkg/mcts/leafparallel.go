@@ -0,0 +1,157 @@
+package mcts
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+)
+
+// Leaf parallelization (see MultithreadLeafParallel)
+// Reference: https://dke.maastrichtuniversity.nl/m.winands/documents/multithreadedMCTS.pdf (Chaslot et al., Parallel Monte-Carlo Tree Search)
+//
+// Only the main search thread walks the tree, every selected leaf is then simulated
+// Limits.NThreads times at once: once by the main thread, and once by every helper,
+// each on its own clone of the GameOperations, kept for the whole search and moved to the
+// position of every leaf. The results are backpropagated by the main thread, so the tree is
+// never modified concurrently, and there are no collisions. Strategies implementing
+// BatchBackpropagator get all of them at once (their sum), the others one by one.
+
+type leafWorkers[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	rands   []*rand.Rand
+	results []R
+	// Clones of the helpers, 'paths' are the moves from the root to their positions
+	clones []O
+	paths  [][]T
+	path   []T
+	wg     sync.WaitGroup
+}
+
+func newLeafWorkers[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](threads int) *leafWorkers[T, S, R, O] {
+	workers := &leafWorkers[T, S, R, O]{
+		rands:   make([]*rand.Rand, threads),
+		results: make([]R, threads),
+		paths:   make([][]T, threads),
+	}
+
+	// Helpers get their own generators, first one belongs to the main thread
+	for i := 1; i < threads; i++ {
+		workers.rands[i] = rand.New(rand.NewSource(SeedGeneratorFn() + int64(i)))
+	}
+	return workers
+}
+
+// Clones 'ops' (with the position of 'path') for every helper
+func (w *leafWorkers[T, S, R, O]) clone(ops O, path []T) {
+	w.clones = make([]O, len(w.results))
+	for i := 1; i < len(w.clones); i++ {
+		w.clones[i] = ops.Clone()
+		if rg, ok := GameOperations[T, S, R, O](w.clones[i]).(RandGameOperations[T, S, R, O]); ok {
+			rg.SetRand(w.rands[i])
+		}
+		w.paths[i] = append(w.paths[i][:0], path...)
+	}
+}
+
+// Moves the clone of the helper 'i' to the position of 'path', only the moves after the common prefix are replayed
+func (w *leafWorkers[T, S, R, O]) sync(i int, path []T) {
+	clone, old := w.clones[i], w.paths[i]
+	common := 0
+	for common < len(old) && common < len(path) && old[common] == path[common] {
+		common++
+	}
+
+	for range len(old) - common {
+		clone.BackTraverse()
+	}
+	for _, move := range path[common:] {
+		clone.Traverse(move)
+	}
+	w.paths[i] = append(old[:0], path...)
+}
+
+// Sum of the results, false if they aren't plain Result values
+func sumResults[R GameResult](results []R) (Result, bool) {
+	sum := Result(0)
+	for _, result := range results {
+		value, ok := any(result).(Result)
+		if !ok {
+			return 0, false
+		}
+		sum += value
+	}
+	return sum, true
+}
+
+// Whether the search should run the leaf-parallel simulations
+func (mcts *MCTS[T, S, R, O, A]) useLeafParallel() bool {
+	return mcts.multithreadPolicy == MultithreadLeafParallel && mcts.Limiter.Limits().NThreads > 1
+}
+
+// Simulates the 'leaf' (with the position of 'ops' set to it) on every worker, and backpropagates
+// all of the results, returns the number of finished simulations
+func (mcts *MCTS[T, S, R, O, A]) searchLeaf(ops O, leaf *NodeBase[T, S], workers *leafWorkers[T, S, R, O]) uint32 {
+	// The outcome is known, simulating it more than once is a waste
+	if leaf.Terminal() || (mcts.solver && leaf.Proven()) {
+		result := mcts.leafResult(ops, leaf)
+		mcts.strategy.Backpropagate(ops, leaf, result)
+		if mcts.solver {
+			mcts.solve(leaf, result)
+		}
+		return 1
+	}
+
+	workers.path = workers.path[:0]
+	for node := leaf; node.Parent != nil; node = node.Parent {
+		workers.path = append(workers.path, node.Move)
+	}
+	slices.Reverse(workers.path)
+
+	// Hidden information is sampled again on every iteration, the clones must get it too
+	_, determinized := GameOperations[T, S, R, O](ops).(ISGameOperations[T, S, R, O])
+	if workers.clones == nil || determinized {
+		workers.clone(ops, workers.path)
+	}
+
+	results := workers.results
+	for i := 1; i < len(results); i++ {
+		workers.wg.Add(1)
+		go func(i int) {
+			defer workers.wg.Done()
+			workers.sync(i, workers.path)
+			results[i] = mcts.evaluate(workers.clones[i], leaf)
+		}(i)
+	}
+	results[0] = mcts.evaluate(ops, leaf)
+	workers.wg.Wait()
+
+	if batch, ok := any(mcts.strategy).(BatchBackpropagator[T, S, O]); ok {
+		if sum, ok := sumResults(results); ok {
+			batch.BackpropagateBatch(ops, leaf, sum, int32(len(results)))
+			if mcts.solver {
+				mcts.solve(leaf, results[0])
+			}
+			return uint32(len(results))
+		}
+	}
+
+	// Every simulation is a visit, the selection applied the virtual loss only once
+	for node := leaf; node.Parent != nil; node = node.Parent {
+		extra := int32(len(results) - 1)
+		node.Stats.AddVvl(extra*VirtualLoss, extra*VirtualLoss)
+	}
+
+	for i, result := range results {
+		// Backpropagation moves the position back to the root
+		if i > 0 {
+			for _, move := range workers.path {
+				ops.Traverse(move)
+			}
+		}
+		mcts.strategy.Backpropagate(ops, leaf, result)
+	}
+
+	if mcts.solver {
+		mcts.solve(leaf, results[0])
+	}
+	return uint32(len(results))
+}
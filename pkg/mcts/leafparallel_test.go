@@ -0,0 +1,166 @@
+package mcts
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// DummyOps with slow rollouts, tracking how many of them run at once
+type SlowRolloutOps struct {
+	DummyOps
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (o *SlowRolloutOps) Rollout() Result {
+	running := o.running.Add(1)
+	defer o.running.Add(-1)
+
+	for peak := o.peak.Load(); running > peak && !o.peak.CompareAndSwap(peak, running); peak = o.peak.Load() {
+	}
+
+	time.Sleep(100 * time.Microsecond)
+	return o.DummyOps.Rollout()
+}
+
+func (o *SlowRolloutOps) Clone() *SlowRolloutOps {
+	return &SlowRolloutOps{DummyOps: *o.DummyOps.Clone(), running: o.running, peak: o.peak}
+}
+
+func checkVirtualLoss(t *testing.T, node *NodeBase[Move, *NodeStats]) {
+	t.Helper()
+	if vl := node.Stats.VirtualLoss(); vl != 0 {
+		t.Fatalf("Expected no virtual loss after the search, got %d", vl)
+	}
+	for i := range node.Children {
+		checkVirtualLoss(t, &node.Children[i])
+	}
+}
+
+func TestLeafParallel(t *testing.T) {
+	const cycles, threads = 10000, 4
+	tree := NewDummyMCTS(MultithreadLeafParallel)
+	tree.SetLimits(DefaultLimits().SetCycles(cycles).SetThreads(threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Every simulation is backpropagated, the last leaf may overshoot the limit
+	if n := tree.Root.Stats.N(); n != int32(tree.Cycles()) || n < cycles || n >= cycles+threads {
+		t.Fatalf("Expected %d root visits (%d cycles), got %d", cycles, tree.Cycles(), n)
+	}
+	if tree.CollisionCount() != 0 {
+		t.Fatalf("Expected no collisions, got %d", tree.CollisionCount())
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+	checkVirtualLoss(t, tree.Root)
+
+	pv, _, _ := tree.Pv(tree.Root, BestChildMostVisits, false)
+	if len(pv) <= 2 {
+		t.Fatalf("No pv found after search, %v", pv)
+	}
+}
+
+func TestLeafParallelConcurrentRollouts(t *testing.T) {
+	const threads = 4
+	ops := &SlowRolloutOps{running: &atomic.Int32{}, peak: &atomic.Int32{}}
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *SlowRolloutOps](0.45),
+		ops,
+		MultithreadLeafParallel,
+		&NodeStats{},
+	)
+	tree.SetLimits(DefaultLimits().SetCycles(200).SetThreads(threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Every leaf is simulated by all threads at once
+	if peak := ops.peak.Load(); peak != threads {
+		t.Fatalf("Expected %d concurrent rollouts, got %d", threads, peak)
+	}
+	if n := tree.Root.Stats.N(); n%threads != 0 {
+		t.Fatalf("Expected the visits to be a multiple of %d, got %d", threads, n)
+	}
+}
+
+// Ops tracking the moves from the root, counting the clones
+type PathOps struct {
+	history []Move
+	clones  *atomic.Int32
+}
+
+func (o *PathOps) Reset()          {}
+func (o *PathOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *PathOps) BackTraverse() {
+	if len(o.history) > 0 {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *PathOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if len(o.history) >= 6 {
+		return 0
+	}
+	parent.Children = make([]NodeBase[Move, *NodeStats], 3)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), len(o.history)+1 >= 6, &NodeStats{})
+	}
+	return 3
+}
+
+func (o *PathOps) Rollout() Result {
+	return 0.5
+}
+
+func (o *PathOps) Clone() *PathOps {
+	o.clones.Add(1)
+	return &PathOps{history: slices.Clone(o.history), clones: o.clones}
+}
+
+func TestLeafParallelWorkerClones(t *testing.T) {
+	const cycles, threads = 4000, 4
+	ops := &PathOps{clones: &atomic.Int32{}}
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *PathOps](0.45),
+		ops,
+		MultithreadLeafParallel,
+		&NodeStats{},
+	)
+
+	// Every clone must be in the position of the simulated leaf
+	var mismatches atomic.Int32
+	tree.SetEvaluator(EvaluatorFunc[Move, *NodeStats, Result, *PathOps](
+		func(ops *PathOps, leaf *NodeBase[Move, *NodeStats]) Result {
+			var path []Move
+			for node := leaf; node.Parent != nil; node = node.Parent {
+				path = append(path, node.Move)
+			}
+			slices.Reverse(path)
+			if !slices.Equal(path, ops.history) {
+				mismatches.Add(1)
+			}
+			return Result(leaf.Move%2) / 2
+		}))
+
+	ops.clones.Store(0)
+	tree.SetLimits(DefaultLimits().SetCycles(cycles).SetThreads(threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if n := mismatches.Load(); n != 0 {
+		t.Fatalf("%d simulations ran in a wrong position", n)
+	}
+	// Helpers keep their clones for the whole search
+	if n := ops.clones.Load(); n > 2*threads {
+		t.Fatalf("Expected the clones to be reused, got %d of them", n)
+	}
+
+	// Single backpropagation of every leaf: all of the visits, and no virtual loss left
+	if n := tree.Root.Stats.N(); n != int32(tree.Cycles()) || n%threads != 0 {
+		t.Fatalf("Expected %d root visits in multiples of %d, got %d", tree.Cycles(), threads, n)
+	}
+	checkVirtualLoss(t, tree.Root)
+}
@@ -0,0 +1,104 @@
+package mcts
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Search lifecycle
+//
+//	Idle -> Running -> Stopping -> (Merging) -> Done -> Running -> ...
+//
+// SearchMultiThreaded starts the search, Stop (or a reached limit) moves it to Stopping,
+// and the main search thread, once every other thread finished, merges the root-parallel
+// trees and marks the search as Done. Waiting for the search to finish happens only in 'wait',
+// used by Synchronize and by everything that modifies the tree (MakeMove, Reset, Save, Load),
+// which are serialized with starting the search, so they are safe to call from any goroutine.
+// None of them may be called from the listeners, since the search waits for the listener.
+
+type SearchState int32
+
+const (
+	SearchIdle     SearchState = iota // no search was started yet
+	SearchRunning                     // search threads are running
+	SearchStopping                    // stop was requested, or a limit was reached, threads are finishing
+	SearchMerging                     // root-parallel trees are merged into the main tree
+	SearchDone                        // search finished, the results are ready
+)
+
+func (s SearchState) String() string {
+	switch s {
+	case SearchIdle:
+		return "Idle"
+	case SearchRunning:
+		return "Running"
+	case SearchStopping:
+		return "Stopping"
+	case SearchMerging:
+		return "Merging"
+	case SearchDone:
+		return "Done"
+	}
+	return "Unknown"
+}
+
+// Single search run, 'done' is closed when the search finishes
+type searchRun struct {
+	done chan struct{}
+	once sync.Once
+}
+
+func (r *searchRun) finish() {
+	r.once.Do(func() { close(r.done) })
+}
+
+type searchLifecycle struct {
+	// Serializes starting the search with the tree modifications
+	mx    sync.Mutex
+	state atomic.Int32
+	run   atomic.Pointer[searchRun]
+}
+
+// Current state of the search, safe to call from any goroutine
+func (mcts *MCTS[T, S, R, O, A]) SearchState() SearchState {
+	return SearchState(mcts.lifecycle.state.Load())
+}
+
+// Moves the search to the state 'to', only if it's currently in the state 'from'
+func (mcts *MCTS[T, S, R, O, A]) transition(from, to SearchState) bool {
+	return mcts.lifecycle.state.CompareAndSwap(int32(from), int32(to))
+}
+
+// Starts a new search run, the lifecycle mutex must be held, and the previous run finished
+func (mcts *MCTS[T, S, R, O, A]) beginRun() *searchRun {
+	run := &searchRun{done: make(chan struct{})}
+	mcts.lifecycle.run.Store(run)
+	mcts.lifecycle.state.Store(int32(SearchRunning))
+	return run
+}
+
+// Marks the search as done, and wakes up everyone waiting for it
+func (mcts *MCTS[T, S, R, O, A]) finishRun(run *searchRun) {
+	mcts.lifecycle.state.Store(int32(SearchDone))
+	if run != nil {
+		run.finish()
+	}
+}
+
+// Blocks until the current search run (if any) is done
+func (mcts *MCTS[T, S, R, O, A]) wait() {
+	if run := mcts.lifecycle.run.Load(); run != nil {
+		<-run.done
+	}
+}
+
+// Stops the search and waits for it, then the tree can be safely modified.
+// Returns the unlock function of the lifecycle, that must be called after the modification.
+func (mcts *MCTS[T, S, R, O, A]) acquireTree() func() {
+	mcts.lifecycle.mx.Lock()
+	if mcts.IsSearching() {
+		mcts.Stop()
+	}
+	mcts.wait()
+	return mcts.lifecycle.mx.Unlock
+}
@@ -0,0 +1,156 @@
+package mcts
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// DummyOps going back to the starting position on Reset, and never reaching a terminal position
+type ResettableOps struct {
+	DummyOps
+}
+
+func (o *ResettableOps) Reset() { o.depth = 0 }
+
+func (o *ResettableOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	parent.Children = make([]NodeBase[Move, *NodeStats], branchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), false, &NodeStats{})
+	}
+	return branchFactor
+}
+
+func (o *ResettableOps) Clone() *ResettableOps {
+	return &ResettableOps{DummyOps: *o.DummyOps.Clone()}
+}
+
+func newResettableMCTS(policy MultithreadPolicy) *MCTS[Move, *NodeStats, Result, *ResettableOps, *UCB1[Move, *NodeStats, Result, *ResettableOps]] {
+	return NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *ResettableOps](0.45),
+		&ResettableOps{},
+		policy,
+		&NodeStats{},
+	)
+}
+
+func TestSearchStates(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	if state := tree.SearchState(); state != SearchIdle || tree.IsSearching() {
+		t.Fatalf("Expected idle tree, got %s", state)
+	}
+
+	// Synchronize without a search returns immediately
+	tree.Synchronize()
+
+	tree.SetLimits(DefaultLimits().SetThreads(4))
+	tree.SearchMultiThreaded()
+	if state := tree.SearchState(); state != SearchRunning || !tree.IsSearching() {
+		t.Fatalf("Expected running search, got %s", state)
+	}
+
+	tree.Stop()
+	if state := tree.SearchState(); state != SearchStopping && state != SearchDone {
+		t.Fatalf("Expected stopping search, got %s", state)
+	}
+
+	tree.Synchronize()
+	if state := tree.SearchState(); state != SearchDone || tree.IsSearching() {
+		t.Fatalf("Expected finished search, got %s", state)
+	}
+
+	// Search stopped by the limits is done as well
+	tree.SetLimits(DefaultLimits().SetCycles(1000).SetThreads(4))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+	if state := tree.SearchState(); state != SearchDone {
+		t.Fatalf("Expected finished search, got %s", state)
+	}
+}
+
+func TestSearchRestartsRunningSearch(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadRootParallel)
+	tree.SetLimits(DefaultLimits().SetThreads(4))
+	tree.SearchMultiThreaded()
+	time.Sleep(10 * time.Millisecond)
+
+	// Previous search is stopped and merged, before the new one starts
+	tree.SearchMultiThreaded()
+	time.Sleep(10 * time.Millisecond)
+	tree.Stop()
+	tree.Synchronize()
+
+	if state := tree.SearchState(); state != SearchDone {
+		t.Fatalf("Expected finished search, got %s", state)
+	}
+	if tree.Root.Stats.N() == 0 {
+		t.Fatal("Expected the searches to visit the root")
+	}
+}
+
+// Reproduces "sync: WaitGroup is reused before previous Wait has returned",
+// many short searches started right after the previous one finished
+func TestRepeatedShortSearches(t *testing.T) {
+	for _, policy := range []MultithreadPolicy{MultithreadTreeParallel, MultithreadRootParallel, MultithreadLeafParallel} {
+		tree := newResettableMCTS(policy)
+		for i := range 300 {
+			tree.SetLimits(DefaultLimits().SetCycles(20).SetThreads(4))
+			tree.SearchMultiThreaded()
+			tree.Synchronize()
+
+			if i%10 == 9 {
+				tree.Reset(false, &NodeStats{})
+			} else if best := tree.BestChild(tree.Root, BestChildMostVisits); best != nil && i%3 == 0 {
+				tree.MakeMove(best.Move)
+			}
+		}
+
+		if tree.IsSearching() {
+			t.Fatalf("Search with policy %d didn't finish", policy)
+		}
+	}
+}
+
+// Every control function called from many goroutines, while the searches are running
+func TestConcurrentSearchControl(t *testing.T) {
+	const workers, iterations = 8, 50
+
+	tree := newResettableMCTS(MultithreadTreeParallel)
+	tree.SetLimits(DefaultLimits().SetThreads(4))
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := range workers {
+		go func(w int) {
+			defer wg.Done()
+			for i := range iterations {
+				switch (w + i) % 6 {
+				case 0:
+					tree.SearchMultiThreaded()
+				case 1:
+					tree.Stop()
+				case 2:
+					tree.Synchronize()
+				case 3:
+					tree.MakeMove(Move(i % branchFactor))
+				case 4:
+					tree.Reset(false, &NodeStats{})
+				case 5:
+					_ = tree.IsSearching()
+					time.Sleep(100 * time.Microsecond)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	tree.Stop()
+	tree.Synchronize()
+
+	if state := tree.SearchState(); state != SearchDone && state != SearchIdle {
+		t.Fatalf("Expected finished search, got %s", state)
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+}
@@ -11,11 +11,15 @@ type StopReason int
 
 const (
 	StopNone      StopReason = iota
-	StopInterrupt            = 1  // Stopped by user, by calling .SetStop(true) or context cancellation
-	StopMovetime             = 2  // Time limit reached
-	StopMemory               = 4  // Memory limit reached
-	StopDepth                = 8  // Depth limit reached
-	StopCycles               = 16 // Cycle limit reached
+	StopInterrupt            = 1   // Stopped by user, by calling .SetStop(true) or context cancellation
+	StopMovetime             = 2   // Time limit reached
+	StopMemory               = 4   // Memory limit reached
+	StopDepth                = 8   // Depth limit reached
+	StopCycles               = 16  // Cycle limit reached
+	StopSolved               = 32  // Game-theoretic value of the root is proven (see MCTS.SetSolver)
+	StopClockSoft            = 64  // Soft deadline of the clock reached (see TimeManager)
+	StopClockHard            = 128 // Hard deadline of the clock reached
+	StopSmart                = 256 // Best root child can no longer be overtaken before the limit (see Limits.SmartStop)
 )
 
 func (sr StopReason) String() string {
@@ -32,6 +36,10 @@ func (sr StopReason) String() string {
 		{StopMemory, "Memory"},
 		{StopDepth, "Depth"},
 		{StopCycles, "Cycles"},
+		{StopSolved, "Solved"},
+		{StopClockSoft, "ClockSoft"},
+		{StopClockHard, "ClockHard"},
+		{StopSmart, "Smart"},
 	}
 
 	var result string
@@ -53,8 +61,15 @@ const (
 	memoryMask int = StopMemory
 	depthMask  int = StopDepth
 	cyclesMask int = StopCycles
+	solvedMask int = StopSolved
+	softMask   int = StopClockSoft
+	hardMask   int = StopClockHard
+	smartMask  int = StopSmart
 )
 
+// How far beyond the cycles and movetime limits the undecided search may go (see UndecidedSelector)
+const undecidedExtension = 0.5
+
 type LimiterLike interface {
 	SetContext(ctx context.Context)
 	// Set the limits
@@ -67,10 +82,14 @@ type LimiterLike interface {
 	SetStop(bool)
 	// Get the stop signal
 	Stop() bool
+	// Signal that the root is solved, the search will stop (unless it's infinite)
+	SetSolved(bool)
 	// Reset the limiter's flags, called on search setup
 	Reset()
 	// Wheter the tree can grow
 	Expand() bool
+	// Maximum number of nodes in the tree (based on the memory limit)
+	MaxSize() uint32
 	// Wheter the search should stop, called in the main search loop
 	Ok(size, depth, cycles uint32) bool
 	// Get the reason why the search was stopped, valid after search ends
@@ -78,26 +97,40 @@ type LimiterLike interface {
 	// Evaluate stop reason based on current state, and set it internally,
 	// this will be called once (by main thread) after search ends, before synchronizing other unfinished threads
 	EvaluateStopReason(size, depth, cycles uint32)
+	// Report the statistics of the root, called periodically by the main search thread
+	UpdateRoot(RootInfo)
 }
 
 type Limiter struct {
-	limits     *Limits
-	Timer      *_Timer
-	nodeSize   uint32
-	maxSize    uint32
-	expand     atomic.Bool
-	stop       atomic.Bool
-	areSetMask int
-	reason     StopReason
-	ctx        context.Context
+	limits       *Limits
+	Timer        *_Timer
+	TimeManager  *TimeManager
+	soft, hard   int // clock deadlines (ms), -1 if the clock isn't set
+	best         int // index of the best root child, only used by the main search thread
+	changes      atomic.Int32
+	undecided    atomic.Bool
+	bestVisits   atomic.Uint32
+	secondVisits atomic.Uint32
+	rootCycles   atomic.Uint32
+	nodeSize     uint32
+	maxSize      uint32
+	expand       atomic.Bool
+	stop         atomic.Bool
+	solved       atomic.Bool
+	areSetMask   int
+	reason       StopReason
+	ctx          context.Context
 }
 
 func NewLimiter(nodesize uint32) *Limiter {
 	limiter := &Limiter{
-		limits:   DefaultLimits(),
-		Timer:    _NewTimer(),
-		nodeSize: nodesize,
-		ctx:      context.Background(),
+		limits:      DefaultLimits(),
+		Timer:       _NewTimer(),
+		TimeManager: NewTimeManager(),
+		soft:        -1,
+		hard:        -1,
+		nodeSize:    nodesize,
+		ctx:         context.Background(),
 	}
 
 	limiter.expand.Store(true)
@@ -108,9 +141,22 @@ func (l *Limiter) Reset() {
 	l.Timer.Movetime(l.limits.Movetime)
 	l.Timer.Reset()
 	l.stop.Store(false)
+	l.solved.Store(false)
 	l.expand.Store(true)
 	l.reason = StopNone
 
+	// Turn the clock into the deadlines
+	l.soft, l.hard = -1, -1
+	if l.limits.Clock.IsSet() {
+		l.soft, l.hard = l.TimeManager.Deadlines(l.limits.Clock)
+	}
+	l.best = -1
+	l.changes.Store(0)
+	l.undecided.Store(false)
+	l.bestVisits.Store(0)
+	l.secondVisits.Store(0)
+	l.rootCycles.Store(0)
+
 	// Calculate 'nodes' based on memory
 	if l.limits.ByteSize != DefaultByteSizeLimit {
 		l.maxSize = uint32(l.limits.ByteSize) / l.nodeSize
@@ -119,7 +165,7 @@ func (l *Limiter) Reset() {
 	}
 
 	// Pre-calculate 'are set' limit mask, see 'Ok' method for more explanation
-	l.areSetMask = toMask(l.Timer.IsSet(), 1) |
+	l.areSetMask = toMask(l.Timer.IsSet() || l.soft > 0, 1) |
 		toMask(l.limits.ByteSize != DefaultByteSizeLimit, 2) |
 		toMask(l.limits.Depth != DefaultDepthLimit, 3) |
 		toMask(l.limits.Cycles != DefaultCyclesLimit, 4)
@@ -149,6 +195,22 @@ func (l *Limiter) EvaluateStopReason(size, depth, cycles uint32) {
 		reason |= StopCycles
 	}
 
+	if okMask&solvedMask == solvedMask {
+		reason |= StopSolved
+	}
+
+	if okMask&softMask == softMask {
+		reason |= StopClockSoft
+	}
+
+	if okMask&hardMask == hardMask {
+		reason |= StopClockHard
+	}
+
+	if okMask&smartMask == smartMask {
+		reason |= StopSmart
+	}
+
 	l.reason = reason
 }
 
@@ -164,6 +226,10 @@ func (l *Limiter) SetStop(v bool) {
 	l.stop.Store(v)
 }
 
+func (l *Limiter) SetSolved(v bool) {
+	l.solved.Store(v)
+}
+
 func (l *Limiter) Stop() bool {
 	select {
 	case <-l.ctx.Done():
@@ -189,6 +255,91 @@ func (l *Limiter) Expand() bool {
 	return l.expand.Load()
 }
 
+func (l *Limiter) MaxSize() uint32 {
+	return l.maxSize
+}
+
+func (l *Limiter) UpdateRoot(root RootInfo) {
+	if l.best != -1 && l.best != root.Best {
+		l.changes.Add(1)
+	}
+	l.best = root.Best
+	l.bestVisits.Store(root.BestVisits)
+	l.secondVisits.Store(root.SecondVisits)
+	l.rootCycles.Store(root.Cycles)
+	l.undecided.Store(root.Undecided)
+}
+
+// Current clock deadlines in ms, the soft one is extended if the best move is unstable,
+// both are -1 if the clock isn't set
+func (l *Limiter) Deadlines() (soft, hard int) {
+	if l.soft <= 0 {
+		return -1, -1
+	}
+	return l.TimeManager.Extend(l.soft, l.hard, l.changes.Load()), l.hard
+}
+
+// Stop signals of the clock: soft and hard deadlines, and the runner-up unable to catch up
+func (l *Limiter) clockMask(cycles uint32) int {
+	if l.soft <= 0 {
+		return 0
+	}
+
+	elapsed := uint32(l.Timer.Deltatime())
+	soft, hard := l.Deadlines()
+	return toMask(elapsed >= uint32(soft), 6) |
+		toMask(elapsed >= uint32(hard), 7) |
+		toMask(elapsed < uint32(soft) && cannotOvertake(l.rootInfo(), visitsLeft(cycles, elapsed, uint32(soft)-elapsed)+l.unseenVisits(cycles)), 8)
+}
+
+// Visits of the root children, that the counts reported with UpdateRoot may not include:
+// the cycles finished since then, and the ones still running on every thread
+func (l *Limiter) unseenVisits(cycles uint32) float64 {
+	return float64(cycles-min(cycles, l.rootCycles.Load())) + float64(max(1, l.limits.NThreads))
+}
+
+// Stop signal of the smart stop: the runner-up can't catch up before the cycles or time limit
+func (l *Limiter) smartStopMask(cycles uint32) int {
+	if !l.limits.SmartStop {
+		return 0
+	}
+
+	left := math.Inf(1)
+	if l.limits.Cycles != DefaultCyclesLimit {
+		left = float64(l.limits.Cycles) - float64(cycles)
+	}
+	if l.Timer.IsSet() {
+		elapsed := uint32(l.Timer.Deltatime())
+		remaining := uint32(max(0, l.limits.Movetime-int(elapsed)))
+		left = min(left, visitsLeft(cycles, elapsed, remaining))
+	}
+
+	if math.IsInf(left, 1) {
+		return 0
+	}
+	return toMask(cannotOvertake(l.rootInfo(), left+l.unseenVisits(cycles)), 8)
+}
+
+// Keeps the undecided search running beyond the cycles and movetime limits (by at most undecidedExtension),
+// and the soft deadline of the clock, other limits (and the stop signal) still apply
+func (l *Limiter) extendUndecided(limitMask int, cycles uint32) int {
+	if !l.undecided.Load() || limitMask&(stopMask|memoryMask|depthMask|solvedMask|hardMask) != 0 {
+		return limitMask
+	}
+
+	if limitMask&timeMask != 0 && float64(l.Timer.Deltatime()) < float64(l.limits.Movetime)*(1+undecidedExtension) {
+		limitMask &^= timeMask
+	}
+	if limitMask&cyclesMask != 0 && float64(cycles) < float64(l.limits.Cycles)*(1+undecidedExtension) {
+		limitMask &^= cyclesMask
+	}
+	return limitMask &^ (softMask | smartMask)
+}
+
+func (l *Limiter) rootInfo() RootInfo {
+	return RootInfo{BestVisits: l.bestVisits.Load(), SecondVisits: l.secondVisits.Load()}
+}
+
 func toMask(val bool, offset int) int {
 	return int(*(*byte)(unsafe.Pointer(&val))) << offset
 }
@@ -207,6 +358,10 @@ func (l *Limiter) LimitMask(size, depth, cycles uint32) int {
 	limitMask |= toMask(l.maxSize <= size, 2)
 	limitMask |= toMask(l.limits.Depth <= int(depth), 3)
 	limitMask |= toMask(l.limits.Cycles <= cycles, 4)
+	limitMask |= toMask(l.solved.Load(), 5)
+	limitMask |= l.clockMask(cycles)
+	limitMask |= l.smartStopMask(cycles)
+	limitMask = l.extendUndecided(limitMask, cycles)
 
 	return limitMask
 }
@@ -220,6 +375,11 @@ func (l *Limiter) OkMask(size, depth, cycles uint32) int {
 	// 3. Memory
 	// 4. Depth
 
+	// Pruning keeps the tree under the memory limit, so it's never exhausted
+	if l.limits.Prune {
+		return limitMask &^ memoryMask
+	}
+
 	// Check the combos:
 	// (time/nodes/cycles or any combination of them) AND memory limit ->
 	// if memory is exhausted, disable expanding of the tree and wait for the other limitation/s
@@ -66,3 +66,100 @@ func TestLimiterCombos(t *testing.T) {
 		t.Error("<Time+Memory failed: ok=", limiter.Ok(100, 1, 1), "expand=", limiter.Expand())
 	}
 }
+
+func TestLimiterSmartStop(t *testing.T) {
+	limiter := NewLimiter(32)
+	root := RootInfo{Best: 0, BestVisits: 600, SecondVisits: 100, Cycles: 650}
+
+	// Disabled by default
+	limiter.SetLimits(DefaultLimits().SetCycles(1000))
+	limiter.Reset()
+	limiter.UpdateRoot(root)
+	if !limiter.Ok(1, 1, 700) {
+		t.Fatal("Smart stop should be disabled by default")
+	}
+
+	// Cycles: the runner-up can get at most 300 more visits
+	limiter.SetLimits(DefaultLimits().SetCycles(1000).SetSmartStop(true))
+	limiter.Reset()
+	limiter.UpdateRoot(root)
+	if !limiter.Ok(1, 1, 500) {
+		t.Fatal("Expected the search to continue, the runner-up can still catch up")
+	}
+	if limiter.Ok(1, 1, 700) {
+		t.Fatal("Expected the search to stop, the runner-up can't catch up")
+	}
+	limiter.EvaluateStopReason(1, 1, 700)
+	if limiter.StopReason() != StopSmart {
+		t.Fatalf("Expected Smart stop reason, got %s", limiter.StopReason())
+	}
+
+	// Movetime: ~1 cycle/ms with ~1000ms left
+	limiter.SetLimits(DefaultLimits().SetMovetime(1000).SetSmartStop(true))
+	limiter.Reset()
+	limiter.UpdateRoot(root)
+	time.Sleep(10 * time.Millisecond)
+	if !limiter.Ok(1, 1, 10) {
+		t.Fatal("Expected the search to continue, the runner-up can still catch up")
+	}
+	limiter.UpdateRoot(RootInfo{Best: 0, BestVisits: 5000, SecondVisits: 100})
+	if limiter.Ok(1, 1, 10) {
+		t.Fatal("Expected the search to stop, the runner-up can't catch up")
+	}
+
+	// Infinite search never stops early
+	limiter.SetLimits(DefaultLimits().SetSmartStop(true))
+	limiter.Reset()
+	limiter.UpdateRoot(RootInfo{Best: 0, BestVisits: 5000, SecondVisits: 0})
+	if !limiter.Ok(1, 1, 10) {
+		t.Fatal("Expected the infinite search to continue")
+	}
+}
+
+func TestSearchSmartStop(t *testing.T) {
+	const cycles = 100000
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+
+	// Single legal move, can't be overtaken
+	tree.Root.Children = tree.Root.Children[:1]
+	tree.SetLimits(DefaultLimits().SetCycles(cycles).SetThreads(4).SetSmartStop(true))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if tree.StopReason() != StopSmart {
+		t.Fatalf("Expected Smart stop reason, got %s", tree.StopReason())
+	}
+	if n := tree.Root.Stats.N(); n >= cycles {
+		t.Fatalf("Expected the search to stop early, got %d visits", n)
+	}
+}
+
+func TestSearchSmartStopMultiThreaded(t *testing.T) {
+	const cycles, threads = 20000, 8
+	stops := 0
+	for range 20 {
+		tree := NewDummyMCTS(MultithreadTreeParallel)
+		tree.SetLimits(DefaultLimits().SetCycles(cycles).SetThreads(threads).SetSmartStop(true))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		if tree.StopReason() != StopSmart {
+			continue
+		}
+		stops++
+
+		// Runner-up can't catch up, even with every cycle left until the limit
+		best, second := int32(0), int32(0)
+		for i := range tree.Root.Children {
+			if n := tree.Root.Children[i].Stats.N(); n > best {
+				best, second = n, best
+			} else if n > second {
+				second = n
+			}
+		}
+		if left := int32(cycles) - int32(tree.Cycles()); second+max(0, left) >= best {
+			t.Fatalf("Smart stop with %d cycles left, but the best child has %d visits and the runner-up %d", left, best, second)
+		}
+	}
+	t.Logf("%d smart stops", stops)
+}
@@ -7,14 +7,17 @@ import (
 )
 
 type Limits struct {
-	Depth    int
-	Nodes    uint32
-	Cycles   uint32
-	Movetime int
-	Infinite bool
-	NThreads int
-	ByteSize int64
-	MultiPv  int
+	Depth     int
+	Nodes     uint32
+	Cycles    uint32
+	Movetime  int
+	Infinite  bool
+	NThreads  int
+	ByteSize  int64
+	MultiPv   int
+	Prune     bool
+	Clock     Clock
+	SmartStop bool
 }
 
 func (l Limits) String() string {
@@ -65,6 +68,20 @@ func (l *Limits) SetMovetime(movetime int) *Limits {
 	return l
 }
 
+// If set to true, the search stops early, once the runner-up can't catch up with the most visited
+// root child before the cycles or movetime limit runs out, the stop reason is StopSmart
+func (l *Limits) SetSmartStop(smart bool) *Limits {
+	l.SmartStop = smart
+	return l
+}
+
+// Set the game clock, the time manager decides how long to search (see TimeManager)
+func (l *Limits) SetClock(clock Clock) *Limits {
+	l.Clock = clock
+	l.Infinite = false
+	return l
+}
+
 // If set to true, will ignore the previous limits and search indefinitely
 func (l *Limits) SetInfinite(infinite bool) {
 	l.Infinite = infinite
@@ -91,6 +108,13 @@ func (l *Limits) SetByteSize(bytesize int64) *Limits {
 	return l
 }
 
+// If set to true, reaching ByteSize frees the least valuable subtrees, instead of freezing the tree,
+// so the search keeps expanding the promising lines. The memory limit never stops such search.
+func (l *Limits) SetPrune(prune bool) *Limits {
+	l.Prune = prune
+	return l
+}
+
 func (l *Limits) InfiniteSize() bool {
 	return l.ByteSize == -1
 }
@@ -0,0 +1,244 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// Rollout policies learned during the search: Move-Average Sampling Technique (MAST)
+// and N-gram Selection Technique (NST)
+// Reference: Finnsson, Björnsson, Simulation-Based Approach to General Game Playing (AAAI 2008)
+// Reference: Tak, Winands, Björnsson, N-Grams and the Last-Good-Reply Policy Applied in General Game Playing (IEEE TCIAIG 2012)
+//
+// The table holds the average result of every move (and with NST, of every sequence of up to N consecutive
+// moves), from the perspective of the player who made it, no matter where it was played. The game operations
+// update it after every playout (see MAST.Update), and choose the rollout moves with it (see MAST.Choose),
+// instead of the uniformly random ones. The table is safe for concurrent use, so every search thread
+// (and every clone of the ops) can share it. Every legal move is valued at each rollout step, so the
+// playouts are slower than the random ones, the policy pays off if they are more informative.
+//
+// Unlike in the papers, where every simulation is credited, the table learns only from the rollouts:
+// the iterations ending in a terminal or solver-proven leaf (and the ones scored by an Evaluator)
+// don't run the Rollout, so they never reach the table.
+
+// Longest n-gram tracked by the table
+const maxNGram = 3
+
+// Table key, the last move is the one being valued, the preceding ones are its context
+type ngram[T MoveLike] struct {
+	moves [maxNGram]T
+	n     uint8
+}
+
+// Average result of the n-gram
+type moveAverage struct {
+	q atomic.Uint64 // sum of the results, with 10^-3 precision
+	n atomic.Int32
+}
+
+type MAST[T MoveLike] struct {
+	// Length of the n-grams: 1 is MAST, 2 and 3 are NST (the move and up to N-1 moves preceding it)
+	N int
+	// Gibbs sampling temperature, used if Epsilon is 0; low values favour the best moves
+	Temperature float64
+	// Epsilon-greedy selection: the best move with probability 1 - Epsilon, a random one otherwise
+	Epsilon float64
+	// Value of the moves not seen yet, optimistic by default (tries every move at least once)
+	DefaultValue float64
+	// Minimum visits of the longer n-grams to be used (NST), the shorter ones are always used
+	MinVisits int32
+
+	// Entries are never removed during the search, their counters are atomic, so the
+	// readers and updaters share the lock, only the new entries need the exclusive one
+	mx    sync.RWMutex
+	table map[ngram[T]]*moveAverage
+}
+
+// MAST with Gibbs sampling (temperature 1)
+func NewMAST[T MoveLike]() *MAST[T] {
+	return &MAST[T]{N: 1, Temperature: 1, DefaultValue: 1, table: make(map[ngram[T]]*moveAverage)}
+}
+
+// NST of the n-grams up to length 'n', with epsilon-greedy selection (epsilon 0.1, min visits 7, as in the paper)
+func NewNST[T MoveLike](n int) *MAST[T] {
+	m := &MAST[T]{Temperature: 1, Epsilon: 0.1, DefaultValue: 1, MinVisits: 7, table: make(map[ngram[T]]*moveAverage)}
+	return m.SetN(n)
+}
+
+func (m *MAST[T]) SetN(n int) *MAST[T] {
+	m.N = min(maxNGram, max(1, n))
+	return m
+}
+
+// Gibbs sampling with the given temperature (disables epsilon-greedy selection)
+func (m *MAST[T]) SetTemperature(temperature float64) *MAST[T] {
+	if temperature <= 0 {
+		panic("[MCTS] MAST.SetTemperature: temperature must be positive")
+	}
+	m.Temperature = temperature
+	m.Epsilon = 0
+	return m
+}
+
+// Epsilon-greedy selection, 0 switches back to Gibbs sampling
+func (m *MAST[T]) SetEpsilon(epsilon float64) *MAST[T] {
+	m.Epsilon = min(1, max(0, epsilon))
+	return m
+}
+
+func (m *MAST[T]) SetDefaultValue(value float64) *MAST[T] {
+	m.DefaultValue = value
+	return m
+}
+
+func (m *MAST[T]) SetMinVisits(visits int32) *MAST[T] {
+	m.MinVisits = max(0, visits)
+	return m
+}
+
+// Forgets every move, for example before a new game
+func (m *MAST[T]) Clear() {
+	m.mx.Lock()
+	clear(m.table)
+	m.mx.Unlock()
+}
+
+// Credits the moves of the playout (the tree moves followed by the rollout moves), 'result' is from
+// the perspective of the player who made moves[0], the players alternate (two-player zero-sum game)
+func (m *MAST[T]) Update(moves []T, result Result) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	for i := range moves {
+		value := result
+		if i%2 == 1 {
+			value = 1 - result
+		}
+
+		for n := 1; n <= m.N && n <= i+1; n++ {
+			key := newNGram(moves[:i], moves[i], n)
+			entry := m.table[key]
+			if entry == nil {
+				m.mx.RUnlock()
+				entry = m.insert(key)
+				m.mx.RLock()
+			}
+			entry.add(value)
+		}
+	}
+}
+
+// Average result and the visits of the 'move' played after the 'history' (the last one is the most recent),
+// only the last n-1 moves of the history are used
+func (m *MAST[T]) Stats(history []T, move T, n int) (Result, int32) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	return m.stats(history, move, n)
+}
+
+func (m *MAST[T]) stats(history []T, move T, n int) (Result, int32) {
+	if n > len(history)+1 || n < 1 || n > maxNGram {
+		return 0, 0
+	}
+	if entry := m.table[newNGram(history, move, n)]; entry != nil {
+		return entry.average()
+	}
+	return 0, 0
+}
+
+// Value of the 'move' after the 'history', the average of its n-grams values (the longer ones only
+// if they were visited at least MinVisits times), DefaultValue if it wasn't played yet
+func (m *MAST[T]) Value(history []T, move T) float64 {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	return m.value(history, move)
+}
+
+func (m *MAST[T]) value(history []T, move T) float64 {
+	q, visits := m.stats(history, move, 1)
+	if visits == 0 {
+		return m.DefaultValue
+	}
+
+	sum, count := float64(q), 1.0
+	for n := 2; n <= m.N; n++ {
+		if q, visits = m.stats(history, move, n); visits >= max(1, m.MinVisits) {
+			sum += float64(q)
+			count++
+		}
+	}
+	return sum / count
+}
+
+// Index of the rollout move chosen among the 'moves' (not empty), 'history' are the moves played
+// before (the last one is the most recent), 'r' is the search thread's generator (see RandGameOperations)
+func (m *MAST[T]) Choose(r *rand.Rand, history []T, moves []T) int {
+	if m.Epsilon > 0 && r.Float64() < m.Epsilon {
+		return r.Intn(len(moves))
+	}
+
+	m.mx.RLock()
+	chosen := 0
+	if m.Epsilon > 0 {
+		bestValue := math.Inf(-1)
+		for i, move := range moves {
+			if value := m.value(history, move); value > bestValue {
+				chosen, bestValue = i, value
+			}
+		}
+	} else {
+		// Gibbs sampling, proportionally to exp(value / temperature), in a single pass: every move
+		// replaces the chosen one with the probability of its weight among the weights seen so far.
+		// Weights are relative to the best value seen (as in sampleChild), so that exp doesn't overflow
+		sum, best := 0.0, math.Inf(-1)
+		for i, move := range moves {
+			value := m.value(history, move) / m.Temperature
+			if value > best {
+				sum *= math.Exp(best - value)
+				best = value
+			}
+			weight := math.Exp(value - best)
+			sum += weight
+			if r.Float64()*sum < weight {
+				chosen = i
+			}
+		}
+	}
+	m.mx.RUnlock()
+	return chosen
+}
+
+// Adds the entry, called without holding the lock
+func (m *MAST[T]) insert(key ngram[T]) *moveAverage {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	entry := m.table[key]
+	if entry == nil {
+		entry = &moveAverage{}
+		m.table[key] = entry
+	}
+	return entry
+}
+
+// N-gram of the 'move' and the last n-1 moves of the 'history'
+func newNGram[T MoveLike](history []T, move T, n int) ngram[T] {
+	key := ngram[T]{n: uint8(n)}
+	copy(key.moves[:n-1], history[len(history)-(n-1):])
+	key.moves[n-1] = move
+	return key
+}
+
+func (a *moveAverage) add(result Result) {
+	a.q.Add(uint64(result * 1e3))
+	a.n.Add(1)
+}
+
+func (a *moveAverage) average() (Result, int32) {
+	n := a.n.Load()
+	if n == 0 {
+		return 0, 0
+	}
+	return Result(a.q.Load()) / 1e3 / Result(n), n
+}
@@ -0,0 +1,127 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func TestMASTUpdate(t *testing.T) {
+	policy := NewNST[Move](2)
+	policy.Update([]Move{1, 2, 3}, 1)
+	policy.Update([]Move{2, 1}, 0.5)
+
+	// Results alternate between the players
+	expected := map[Move][2]float64{1: {1.5 / 2, 2}, 2: {0.5 / 2, 2}, 3: {1, 1}}
+	for move, stats := range expected {
+		if q, n := policy.Stats(nil, move, 1); math.Abs(float64(q)-stats[0]) > 1e-3 || float64(n) != stats[1] {
+			t.Fatalf("Move %d: expected %v, got %f, %d", move, stats, q, n)
+		}
+	}
+
+	// Bigrams
+	if q, n := policy.Stats([]Move{1}, 2, 2); q != 0 || n != 1 {
+		t.Fatalf("Expected the bigram (1, 2) to be lost once, got %f, %d", q, n)
+	}
+	if _, n := policy.Stats([]Move{3}, 2, 2); n != 0 {
+		t.Fatal("Expected no visits of the unplayed bigram")
+	}
+
+	// Longer n-grams are used only once they have enough visits
+	if v := policy.Value([]Move{1}, 2); math.Abs(v-0.25) > 1e-3 {
+		t.Fatalf("Expected the unigram value, got %f", v)
+	}
+	policy.SetMinVisits(1)
+	if v := policy.Value([]Move{1}, 2); math.Abs(v-0.125) > 1e-3 {
+		t.Fatalf("Expected the average of the unigram and bigram, got %f", v)
+	}
+	if v := policy.Value(nil, 4); v != policy.DefaultValue {
+		t.Fatalf("Expected the default value of an unseen move, got %f", v)
+	}
+
+	policy.Clear()
+	if _, n := policy.Stats(nil, 1, 1); n != 0 {
+		t.Fatal("Expected an empty table after Clear")
+	}
+}
+
+func TestMASTChoose(t *testing.T) {
+	const samples = 10000
+	moves := []Move{0, 1, 2, 3}
+	r := rand.New(rand.NewSource(1))
+
+	count := func(policy *MAST[Move]) []int {
+		counts := make([]int, len(moves))
+		for range samples {
+			counts[policy.Choose(r, nil, moves)]++
+		}
+		return counts
+	}
+
+	// Move 2 always wins, the others always lose
+	policy := NewMAST[Move]().SetTemperature(0.1)
+	for _, move := range moves {
+		result := Result(0)
+		if move == 2 {
+			result = 1
+		}
+		policy.Update([]Move{move}, result)
+	}
+
+	if counts := count(policy); counts[2] < samples*9/10 {
+		t.Fatalf("Gibbs sampling: expected the winning move mostly, got %v", counts)
+	}
+	if counts := count(policy.SetTemperature(1000)); counts[2] > samples*3/10 {
+		t.Fatalf("Gibbs sampling: expected uniform choices at high temperature, got %v", counts)
+	}
+	// exp(1 / 0.001) overflows, the weights must not
+	if counts := count(policy.SetTemperature(0.001)); counts[2] != samples {
+		t.Fatalf("Gibbs sampling: expected only the winning move at low temperature, got %v", counts)
+	}
+
+	// Best move with probability 1 - epsilon + epsilon / moves
+	counts := count(policy.SetEpsilon(0.2))
+	if expected := samples * 0.85; math.Abs(float64(counts[2])-expected) > samples*0.02 {
+		t.Fatalf("Epsilon-greedy: expected about %.0f choices of the winning move, got %v", expected, counts)
+	}
+
+	// Unseen moves are tried first
+	counts = count(NewMAST[Move]().SetEpsilon(0.01).SetDefaultValue(2))
+	if counts[0] < samples*9/10 {
+		t.Fatalf("Expected the first unseen move, got %v", counts)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic for a non-positive temperature")
+		}
+	}()
+	policy.SetTemperature(0)
+}
+
+func TestMASTConcurrentUpdate(t *testing.T) {
+	const (
+		threads = 8
+		updates = 1000
+	)
+
+	policy := NewNST[Move](3)
+	var wg sync.WaitGroup
+	for range threads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(SeedGeneratorFn()))
+			for range updates {
+				policy.Update([]Move{1, 2, 3}, 1)
+				policy.Choose(r, []Move{1, 2}, []Move{3, 4})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if q, n := policy.Stats([]Move{1, 2}, 3, 3); n != threads*updates || math.Abs(float64(q)-1) > 1e-3 {
+		t.Fatalf("Expected %d wins of the trigram, got %f, %d", threads*updates, q, n)
+	}
+}
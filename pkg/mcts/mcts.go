@@ -96,14 +96,26 @@ type MCTS[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R
 	Limiter           LimiterLike
 	Root              *NodeBase[T, S]
 	size              atomic.Uint32
-	wg                sync.WaitGroup
+	lifecycle         searchLifecycle
+	helpers           sync.WaitGroup // search threads, other than the main one
 	collisionCount    atomic.Int32
 	multithreadPolicy MultithreadPolicy
 	roots             []*NodeBase[T, S]
-	merged            atomic.Bool
 	strategy          A
 	ops               O
 	statsmx           sync.Mutex
+	table             *TranspositionTable[T, S]
+	evaluator         Evaluator[T, S, R, O]
+	solver            bool
+	widening          *wideningParams
+	pool              NodeAllocator[T, S]
+	pruneMx           sync.RWMutex
+	ponder            ponderState
+	selector          BestChildSelector[T, S]
+	noise             *rootNoise
+	temperature       TemperatureSchedule
+	ply               atomic.Int32
+	sampler           sampler
 }
 
 // Create new base tree
@@ -127,8 +139,17 @@ func NewMTCS[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S
 		panic("[MCTS] NewMCTS: defaultStats cannot be nil")
 	}
 
-	// Set IsSearching to false
-	mcts.Limiter.Stop()
+	if _, ok := any(strategy).(multiPlayerStrategy); ok {
+		if _, ok := GameOperations[T, S, R, O](operations).(MultiPlayerGameOperations[T, S, R, O]); !ok {
+			panic("[MCTS] NewMCTS: multi-player strategy requires GameOperations implementing MultiPlayerGameOperations")
+		}
+	}
+
+	if _, ok := GameOperations[T, S, R, O](operations).(ISGameOperations[T, S, R, O]); ok {
+		if _, ok := any(strategy).(DeterminizedStrategy[T, S, R, O]); !ok {
+			panic("[MCTS] NewMCTS: GameOperations implementing ISGameOperations require a DeterminizedStrategy (see ISUCB1)")
+		}
+	}
 
 	// If that's random-based playouts, attach random number generator
 	if rg, ok := GameOperations[T, S, R, O](operations).(RandGameOperations[T, S, R, O]); ok {
@@ -164,7 +185,7 @@ func (mcts *MCTS[T, S, R, O, A]) tryExpandingWarn(node *NodeBase[T, S]) bool {
 	}
 
 	shouldWarn := false
-	v := mcts.ops.ExpandNode(node)
+	v := mcts.expandNode(node, mcts.ops)
 	if v > 0 && len(node.Children) == int(v) {
 		// Update the size
 		mcts.size.Add(v)
@@ -177,6 +198,26 @@ func (mcts *MCTS[T, S, R, O, A]) tryExpandingWarn(node *NodeBase[T, S]) bool {
 	return shouldWarn
 }
 
+// Calls the user-defined ExpandNode, and if the expansion succeeded,
+// sets the players and move priors, and links the new children with their transpositions
+func (mcts *MCTS[T, S, R, O, A]) expandNode(node *NodeBase[T, S], ops O) uint32 {
+	v := ops.ExpandNode(node)
+	if v == 0 || len(node.Children) != int(v) {
+		return v
+	}
+
+	if mcts.widening != nil {
+		mcts.reserveChildren(node, mcts.widening.maxChildren)
+	}
+	mcts.setChance(node, ops)
+	mcts.setPlayers(node, node.Children, ops)
+	mcts.setPriors(node, 0, ops)
+	if mcts.useTranspositions() {
+		mcts.linkTranspositions(node, node.Children, ops)
+	}
+	return v
+}
+
 // Mark the root position as terminal
 func (mcts *MCTS[T, S, R, O, A]) SetTerminal(isTerminal bool) {
 	if isTerminal {
@@ -185,8 +226,8 @@ func (mcts *MCTS[T, S, R, O, A]) SetTerminal(isTerminal bool) {
 	}
 }
 
-// The number of times a node was chosen, but it was already being expanded.
-// Resulting in a 'waiting' state of the search thread
+// The number of times a node was chosen, but it was already being expanded by other thread.
+// Such thread doesn't wait for the expansion, it rolls out from the unexpanded leaf instead
 func (mcts *MCTS[T, S, R, O, A]) CollisionCount() int32 {
 	return mcts.collisionCount.Load()
 }
@@ -234,13 +275,20 @@ func (mcts *MCTS[T, S, R, O, A]) SetMultithreadPolicy(policy MultithreadPolicy)
 	mcts.multithreadPolicy = policy
 }
 
+// Whether the search was started and isn't done yet (see SearchState)
 func (mcts *MCTS[T, S, R, O, A]) IsSearching() bool {
-	return !mcts.Limiter.Stop()
+	switch mcts.SearchState() {
+	case SearchRunning, SearchStopping, SearchMerging:
+		return true
+	}
+	return false
 }
 
-// Stop the search
+// Stop the search, safe to call from any goroutine (and the listeners),
+// doesn't wait for the search threads, use Synchronize for that
 func (mcts *MCTS[T, S, R, O, A]) Stop() {
 	mcts.Limiter.SetStop(true)
+	mcts.transition(SearchRunning, SearchStopping)
 }
 
 // Maxiumum depth reach during the search, note that usually MaxDepth != len(pv)
@@ -314,7 +362,26 @@ func (mcts *MCTS[T, S, R, O, A]) Size() uint32 {
 
 // Returns an approximation of memory usage of the tree structure
 func (mcts *MCTS[T, S, R, O, A]) MemoryUsage() uint32 {
-	return mcts.Size()*uint32(unsafe.Sizeof(NodeBase[T, S]{})) + uint32(unsafe.Sizeof(MCTS[T, S, R, O, A]{}))
+	usage := mcts.Size()*uint32(unsafe.Sizeof(NodeBase[T, S]{})) + uint32(unsafe.Sizeof(MCTS[T, S, R, O, A]{}))
+	if mcts.pool != nil {
+		// Nodes come from the pool, report what it really allocated
+		usage = mcts.pool.MemoryUsage() + uint32(unsafe.Sizeof(MCTS[T, S, R, O, A]{}))
+	}
+	if mcts.table != nil {
+		usage += mcts.table.MemoryUsage()
+	}
+	return usage
+}
+
+// Size passed to the limiter, in nodes. Positions stored in the
+// transposition table are converted to the equivalent number of nodes,
+// so that they count towards the memory limit.
+func (mcts *MCTS[T, S, R, O, A]) limiterSize() uint32 {
+	size := mcts.Size()
+	if mcts.table != nil {
+		size += mcts.table.MemoryUsage() / uint32(unsafe.Sizeof(NodeBase[T, S]{}))
+	}
+	return size
 }
 
 // Creates a deep copy of the tree
@@ -323,7 +390,13 @@ func (mcts *MCTS[T, S, R, O, A]) Clone() *MCTS[T, S, R, O, A] {
 		Root:              mcts.Root.Clone(nil),
 		ops:               mcts.ops.Clone(),
 		strategy:          mcts.strategy,
+		evaluator:         mcts.evaluator,
+		solver:            mcts.solver,
+		widening:          mcts.widening,
+		pool:              mcts.pool,
 		multithreadPolicy: mcts.multithreadPolicy,
+		temperature:       mcts.temperature,
+		selector:          mcts.selector,
 		listener:          &StatsListener[T]{},
 		Limiter:           NewLimiter(uint32(unsafe.Sizeof(NodeBase[T, S]{}))),
 	}
@@ -332,19 +405,31 @@ func (mcts *MCTS[T, S, R, O, A]) Clone() *MCTS[T, S, R, O, A] {
 	clone.TreeStats.cycles.Store(mcts.TreeStats.cycles.Load())
 	clone.TreeStats.maxdepth.Store(mcts.TreeStats.maxdepth.Load())
 	clone.collisionCount.Store(mcts.collisionCount.Load())
-	clone.merged.Store(mcts.merged.Load())
 	clone.size.Store(mcts.size.Load())
+	clone.ply.Store(mcts.ply.Load())
+	clone.SetRootNoise(mcts.RootNoise())
+
+	// Cloned nodes have their own statistics, so start with an empty table
+	if mcts.table != nil {
+		clone.table = NewTranspositionTable[T, S]()
+	}
 
 	return clone
 }
 
-// Tries to make given 'move' a new root, if it failes, does nothing
+// Tries to make given 'move' a new root, if it failes, does nothing.
+// If the search is running, stops it first, safe to call from any goroutine.
+// While pondering, the expected reply continues the search with the normal limits (see Ponder).
 func (mcts *MCTS[T, S, R, O, A]) MakeMove(move T) bool {
-	// If the search is running, stop it first
-	if mcts.IsSearching() {
-		mcts.Stop()
-		mcts.Synchronize()
+	defer mcts.acquireTree()()
+
+	// Opponent's move ends the pondering
+	ponderhit := false
+	if mcts.endPondering() {
+		best := mcts.BestChild(mcts.Root, BestChildDefault)
+		ponderhit = best != nil && best.Move == move
 	}
+	mcts.ponder.hit.Store(false)
 
 	// Sanitity check
 	if mcts.Root == nil || len(mcts.Root.Children) == 0 {
@@ -364,7 +449,11 @@ func (mcts *MCTS[T, S, R, O, A]) MakeMove(move T) bool {
 		return false
 	}
 
-	oldRoot := mcts.Root
+	oldRoot, previous := mcts.Root, newRoot
+	if mcts.usePool() {
+		// Keep the new root, when the rest of the tree is released
+		newRoot = detachNode(newRoot)
+	}
 	mcts.Root = newRoot
 	mcts.size.Store(uint32(countTreeNodes(newRoot)))
 	mcts.maxdepth.Store(max(0, int32(mcts.MaxDepth()-1)))
@@ -373,18 +462,33 @@ func (mcts *MCTS[T, S, R, O, A]) MakeMove(move T) bool {
 	// Detach the new root from its parent
 	newRoot.Parent = nil
 
-	// Clear the children of the old root, to make them available for GC
-	oldRoot.Children = nil
+	// Positions of the released subtrees can't be reached anymore
+	if mcts.table != nil {
+		mcts.table.Retain(newRoot)
+	}
+	mcts.retainBestSequence(newRoot, previous)
+
+	// Clear the children of the old root, to make them available for GC (or the pool)
+	if mcts.usePool() {
+		mcts.pool.Release(oldRoot)
+	} else {
+		oldRoot.Children = nil
+	}
+
+	mcts.ply.Add(1)
+
+	if ponderhit && !newRoot.Terminal() {
+		mcts.ponder.hit.Store(true)
+		mcts.startSearch()
+	}
 	return true
 }
 
-// Remove previous tree & update game ops state
+// Remove previous tree & update game ops state.
+// If the search is running, stops it first, safe to call from any goroutine.
 func (mcts *MCTS[T, S, R, O, A]) Reset(isTerminated bool, defaultStats S) {
-	// Discard running search
-	if mcts.IsSearching() {
-		mcts.Stop()
-		mcts.Synchronize()
-	}
+	defer mcts.acquireTree()()
+	mcts.endPondering()
 
 	if any(defaultStats) == nil {
 		panic("[MCTS] Reset: defaultStats cannot be nil")
@@ -392,9 +496,17 @@ func (mcts *MCTS[T, S, R, O, A]) Reset(isTerminated bool, defaultStats S) {
 
 	// Reset game state and make new root
 	mcts.ops.Reset()
+	if mcts.usePool() {
+		mcts.pool.Release(mcts.Root)
+	}
 	mcts.Root = nil
 	mcts.Root = newRootNode[T](isTerminated, defaultStats)
 	mcts.size.Store(1)
+	mcts.ply.Store(0)
+	mcts.retainBestSequence(nil, nil)
+	if mcts.table != nil {
+		mcts.table.Clear()
+	}
 
 	// insignificant optimization
 	if mcts.tryExpandingWarn(mcts.Root) {
@@ -405,7 +517,7 @@ func (mcts *MCTS[T, S, R, O, A]) Reset(isTerminated bool, defaultStats S) {
 // 'the best move' in the position
 func (mcts *MCTS[T, S, R, O, A]) BestMove() T {
 	var signature T
-	if bestChild := mcts.BestChild(mcts.Root, BestChildMostVisits); bestChild != nil {
+	if bestChild := mcts.BestChild(mcts.Root, BestChildDefault); bestChild != nil {
 		signature = bestChild.Move
 	}
 	return signature
@@ -413,83 +525,52 @@ func (mcts *MCTS[T, S, R, O, A]) BestMove() T {
 
 // Current evaluation of the position
 func (mcts *MCTS[T, S, R, O, A]) RootScore() Result {
-	if bestChild := mcts.BestChild(mcts.Root, BestChildMostVisits); bestChild != nil {
-		return bestChild.Stats.Q() / Result(bestChild.Stats.N())
+	// Expectation over the outcomes, the root's stats are from the enemy's perspective
+	if mcts.Root.Chance() && mcts.Root.Stats.N() > 0 {
+		return 1 - Result(nodeValue(mcts.Root))
+	}
+	if bestChild := mcts.BestChild(mcts.Root, BestChildDefault); bestChild != nil {
+		if value, ok := proofValue(bestChild.ProofState()); ok {
+			return value
+		}
+		return Result(nodeValue(bestChild))
 	}
 	return Result(math.NaN())
 }
 
-// Return best child, based on the policy
+// Return best child, based on the policy (see BestChildSelector)
 func (mcts *MCTS[T, S, R, O, A]) BestChild(node *NodeBase[T, S], policy BestChildPolicy) *NodeBase[T, S] {
-	var bestChild *NodeBase[T, S]
-	var child *NodeBase[T, S]
-	maxVisits := 0
-
-	if !node.Expanded() || len(node.Children) == 0 {
+	if !node.Expanded() {
 		return nil
 	}
 
-	// DEBUG
-	// rootTurn := mcts.Root.Turn() == node.Turn()
-	// if rootTurn {
-	// 	fmt.Print("Root's turn")
-	// } else {
-	// 	fmt.Print("Enemy's turn")
-	// }
-	// fmt.Printf(" wr=%0.2f\n", float64(node.Stats.Q())/float64(node.Stats.N()))
-	// for i := range node.Children {
-	// 	ch := &node.Children[i]
-	// 	fmt.Printf("%d. %v v=%d (wr=%.2f)\n",
-	// 		i+1, ch.Move, ch.Stats.N(),
-	// 		float64(ch.Stats.Q())/float64(ch.Stats.N()),
-	// 	)
-	// }
-
-	switch policy {
-	case BestChildMostVisits:
-		for i := 0; i < len(node.Children); i++ {
-			child = &node.Children[i]
-			if v := int(child.Stats.RealVisits()); v > maxVisits && v > 0 {
-				maxVisits = int(child.Stats.RealVisits())
-				bestChild = child
-			}
-		}
-	case BestChildWinRate:
-		// the child we choose should have at least 20% of the max visit count (from the neighbours)
-		const (
-			minVisitsPercentageThreshold = 0
-			minVisitsThreshold           = 10
-		)
-
-		bestWinRate := -1.0
-
-		// Get max visits out the children
-		for i := 0; i < len(node.Children); i++ {
-			maxVisits = max(int(node.Children[i].Stats.N()), maxVisits)
-		}
-
-		// Go through the children
-		for i := 0; i < len(node.Children); i++ {
-			child = &node.Children[i]
-			real := child.Stats.RealVisits()
-			if real > minVisitsThreshold && real > int32(minVisitsPercentageThreshold*float64(maxVisits)) {
+	children := node.VisibleChildren()
+	if len(children) == 0 {
+		return nil
+	}
 
-				// We optimize the winning chances, looking from the root's perspective
-				var winRate float64 = float64(child.Stats.Q()) / float64(child.Stats.N())
+	// Outcomes aren't decisions, follow the most likely one
+	if node.Chance() {
+		return likelyOutcome(node)
+	}
 
-				if winRate > bestWinRate {
-					bestWinRate = winRate
-					bestChild = child
-				}
-			}
-		}
+	// Proven values decide first (see MCTS.SetSolver)
+	if proven := provenBestChild(node, mcts.childrenComplete(node)); proven != nil {
+		return proven
 	}
 
-	// if bestChild != nil {
-	// 	fmt.Println("Chose", bestChild.Move)
-	// }
+	var best *NodeBase[T, S]
+	if policy == BestChildSampled {
+		best = mcts.sampleChild(node, children)
+	} else {
+		best = mcts.selectorFor(policy).Select(node, selectable(children))
+	}
 
-	return bestChild
+	// Every visible child loses, but the progressive widening may still add a better one
+	if best == nil {
+		best = provenBestChild(node, true)
+	}
+	return best
 }
 
 type PvResult[T MoveLike, S NodeStatsLike[S]] struct {
@@ -497,6 +578,10 @@ type PvResult[T MoveLike, S NodeStatsLike[S]] struct {
 	Pv       []T
 	Terminal bool
 	Draw     bool
+	// Proven value of the line from the root's side to move perspective (see MCTS.SetSolver),
+	// and the number of plies to the end of the game (including the Root move)
+	Proof      ProofState
+	ProofPlies int
 }
 
 // Returns 'pvCount' best move lines, specified in the limits
@@ -507,13 +592,50 @@ func (mcts *MCTS[T, S, R, O, A]) MultiPv(policy BestChildPolicy) []PvResult[T, S
 
 	pvCount := mcts.Limiter.Limits().MultiPv
 	multipv := make([]PvResult[T, S], 0, pvCount)
-	child_count := len(mcts.Root.Children)
-	root_nodes := make([]*NodeBase[T, S], child_count)
-	for i := range child_count {
-		root_nodes[i] = &mcts.Root.Children[i]
+	root_nodes := mcts.orderChildren(mcts.Root, policy, pvCount)
+	child_count := len(root_nodes)
+
+	for i := range pvCount {
+		// Get the Pv from this 'Root'
+		if i < child_count {
+			pv, terminal, draw := mcts.Pv(root_nodes[i], policy, true)
+			proof, plies := root_nodes[i].Proof()
+			if proof != ProofUnknown {
+				plies++
+			}
+
+			multipv = append(multipv, PvResult[T, S]{
+				Root:       root_nodes[i],
+				Pv:         pv,
+				Terminal:   terminal,
+				Draw:       draw || proof == ProofDraw,
+				Proof:      proof,
+				ProofPlies: plies,
+			})
+		} else {
+			break
+		}
+	}
+
+	return multipv
+}
+
+// Children of the node, ordered by the policy: the best child first, and then repeatedly the best
+// of the rest, until 'count' of them are ordered, the rest (and the ones the selector never chooses)
+// are ordered by the visits
+func (mcts *MCTS[T, S, R, O, A]) orderChildren(node *NodeBase[T, S], policy BestChildPolicy, count int) []*NodeBase[T, S] {
+	// Children are published by the Expanded flag, other thread may be writing them
+	if !node.Expanded() {
+		return nil
 	}
 
-	slices.SortFunc(root_nodes, func(a *NodeBase[T, S], b *NodeBase[T, S]) int {
+	children := node.VisibleChildren()
+	rest := make([]*NodeBase[T, S], len(children))
+	for i := range children {
+		rest[i] = &children[i]
+	}
+
+	slices.SortStableFunc(rest, func(a *NodeBase[T, S], b *NodeBase[T, S]) int {
 		va, vb := a.Stats.N(), b.Stats.N()
 		if va < vb {
 			return 1
@@ -523,22 +645,22 @@ func (mcts *MCTS[T, S, R, O, A]) MultiPv(policy BestChildPolicy) []PvResult[T, S
 		return 0
 	})
 
-	for i := range pvCount {
-		// Get the Pv from this 'Root'
-		if i < child_count {
-			pv, terminal, draw := mcts.Pv(root_nodes[i], policy, true)
-			multipv = append(multipv, PvResult[T, S]{
-				Root:     root_nodes[i],
-				Pv:       pv,
-				Terminal: terminal,
-				Draw:     draw,
-			})
-		} else {
+	ordered := make([]*NodeBase[T, S], 0, len(rest))
+	next := mcts.BestChild(node, policy)
+	selector := mcts.selectorFor(policy)
+	for next != nil && len(ordered) < count {
+		index := slices.Index(rest, next)
+		if index == -1 {
 			break
 		}
-	}
 
-	return multipv
+		ordered = append(ordered, next)
+		rest = slices.Delete(rest, index, index+1)
+		next = selector.Select(node, slices.DeleteFunc(slices.Clone(rest), func(child *NodeBase[T, S]) bool {
+			return child.ProvenLoss()
+		}))
+	}
+	return append(ordered, rest...)
 }
 
 // Get the principal variation (ie. the best sequence of moves)
@@ -556,14 +678,14 @@ func (mcts *MCTS[T, S, R, O, A]) PvNodes(root *NodeBase[T, S], policy BestChildP
 		pv = append(pv, root)
 	}
 
-	if len(root.Children) == 0 {
+	if !root.Expanded() || len(root.VisibleChildren()) == 0 {
 		// If there are no children, we cannot go further
 		return pv, root.Terminal()
 	}
 
 	// Simply select 'best child' until we don't have any children
 	// or the node is nil
-	for len(node.Children) > 0 && node.Expanded() {
+	for node.Expanded() && len(node.VisibleChildren()) > 0 {
 		node = mcts.BestChild(node, policy)
 		if node == nil {
 			break
@@ -576,17 +698,41 @@ func (mcts *MCTS[T, S, R, O, A]) PvNodes(root *NodeBase[T, S], policy BestChildP
 			mate = true
 			break
 		}
+
+		// In graph mode, this position might have been explored deeper
+		// through a different move order, so continue in that subtree
+		node = mcts.transposedNode(node)
 	}
 
 	return pv, mate
 }
 
+// If the node isn't expanded, but its position was reached and expanded through
+// a different move order, returns that node, otherwise returns given node
+func (mcts *MCTS[T, S, R, O, A]) transposedNode(node *NodeBase[T, S]) *NodeBase[T, S] {
+	if mcts.table == nil || node.Expanded() {
+		return node
+	}
+
+	if owner := mcts.table.Owner(node.Stats); owner != nil && owner.Expanded() {
+		return owner
+	}
+	return node
+}
+
 // Get the pricipal variation, but only the moves, returns (moves, mate, draw)
 func (mcts *MCTS[T, S, R, O, A]) Pv(root *NodeBase[T, S], policy BestChildPolicy, includeRoot bool) ([]T, bool, bool) {
 	if root == nil {
 		return nil, false, false
 	}
 
+	// Best complete sequence (single-player), ends with a terminal position
+	if policy == BestChildMaxScore {
+		if moves, ok := mcts.bestSequence(root, includeRoot); ok {
+			return moves, true, false
+		}
+	}
+
 	var node *NodeBase[T, S]
 	nodes, mate := mcts.PvNodes(root, policy, includeRoot)
 	pv := make([]T, len(nodes))
@@ -596,5 +742,5 @@ func (mcts *MCTS[T, S, R, O, A]) Pv(root *NodeBase[T, S], policy BestChildPolicy
 	}
 
 	// pv might be empty, so we must check if the node is valid
-	return pv, mate, (mate && node != nil && node.Stats.AvgQ() == 0.5)
+	return pv, mate, (mate && node != nil && (node.ProofState() == ProofDraw || node.Stats.AvgQ() == 0.5))
 }
@@ -254,7 +254,7 @@ func deepCompare(n1, n2 *NodeBase[Move, *NodeStats]) bool {
 	if n1.Move != n2.Move {
 		return false
 	}
-	if n1.Flags != n2.Flags {
+	if n1.Flags != n2.Flags || n1.Player != n2.Player || n1.proof != n2.proof || n1.Prob != n2.Prob {
 		return false
 	}
 	if n1.Stats.N() != n2.Stats.N() || n1.Stats.RawQ() != n2.Stats.RawQ() {
@@ -0,0 +1,126 @@
+package mcts
+
+import "math"
+
+// N-player games support, using max^n backpropagation
+// Reference: https://www.aaai.org/Papers/AAAI/1986/AAAI86-025.pdf (max^n),
+// https://dke.maastrichtuniversity.nl/m.winands/documents/multiplayerMCTS.pdf
+//
+// Every node records the player that made the move leading to it (NodeBase.Player),
+// the result of the playout holds a reward for every player, and each node is credited
+// with the reward of its player. So, the statistics of a node are always from the
+// perspective of the player choosing it, and the selection simply maximizes them.
+// The children of the root hold the values of the player to move at the root.
+
+// Game operations of the game with more than 2 players, required by the MaxN strategy
+type MultiPlayerGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	GameOperations[T, S, R, O]
+	// Number of players in the game
+	Players() int
+	// Index of the player to move in current position, in range [0, Players())
+	Turn() int
+}
+
+// Result of the playout in N-player game
+type MultiPlayerResult interface {
+	// Reward of given player, in range [0, 1]
+	Reward(player int) Result
+}
+
+// Rewards of every player, Rewards[i] belongs to player i
+type Rewards []Result
+
+func (r Rewards) Reward(player int) Result {
+	return r[player]
+}
+
+// Sets the player of freshly created 'children' of the 'node' to the player to move in 'node'
+func (mcts *MCTS[T, S, R, O, A]) setPlayers(node *NodeBase[T, S], children []NodeBase[T, S], ops O) {
+	mpOps, ok := GameOperations[T, S, R, O](ops).(MultiPlayerGameOperations[T, S, R, O])
+	if !ok {
+		return
+	}
+
+	player := uint8(mpOps.Turn())
+	if node.Chance() {
+		// Outcomes share the perspective of the chance node
+		player = node.Player
+	}
+	for i := range children {
+		children[i].Player = player
+	}
+}
+
+// Strategies requiring MultiPlayerGameOperations
+type multiPlayerStrategy interface {
+	multiPlayer()
+}
+
+// Max^n strategy, UCB1 selection with backpropagation crediting
+// every node with the reward of the player that moved into it
+type MaxN[T MoveLike, S NodeStatsLike[S], R MultiPlayerResult, O GameOperations[T, S, R, O]] struct {
+	ExplorationParam float64
+}
+
+func NewMaxN[T MoveLike, S NodeStatsLike[S], R MultiPlayerResult, O GameOperations[T, S, R, O]](explorationParam float64) *MaxN[T, S, R, O] {
+	return &MaxN[T, S, R, O]{ExplorationParam: explorationParam}
+}
+
+func (m *MaxN[T, S, R, O]) multiPlayer() {}
+
+func (m *MaxN[T, S, R, O]) SetExplorationParam(c float64) *MaxN[T, S, R, O] {
+	m.ExplorationParam = max(0, c)
+	return m
+}
+
+func (m *MaxN[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := float64(-1)
+	index := 0
+	lnParentVisits := math.Log(float64(parent.Stats.N()))
+	var child *NodeBase[T, S]
+	var visits, vl int32
+
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
+		child = &children[i]
+		visits, vl = child.Stats.GetVvl()
+
+		// Pick the unvisited one
+		if visits-vl == 0 {
+			return child
+		}
+
+		// Children's stats are from the perspective of the player to move in 'parent'
+		ucb1 := float64(child.Stats.Q())/float64(visits) +
+			m.ExplorationParam*math.Sqrt(lnParentVisits/float64(visits))
+
+		if ucb1 > best {
+			best = ucb1
+			index = i
+		}
+	}
+
+	return &children[index]
+}
+
+func (m *MaxN[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result R) {
+	for node != nil {
+
+		// Reverse virtual loss for non-root
+		if node.Parent != nil {
+			node.Stats.AddVvl(1-VirtualLoss, -VirtualLoss)
+		} else {
+			node.Stats.AddVvl(1, 0)
+		}
+
+		// No perspective flipping, each node gets its player's reward
+		node.Stats.AddQ(result.Reward(int(node.Player)))
+
+		node = node.Parent
+		ops.BackTraverse()
+	}
+}
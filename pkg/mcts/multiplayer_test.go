@@ -0,0 +1,137 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// 3 player game, each player makes 'mpMovesPerPlayer' moves (numbers 0..3),
+// the reward of the player is the sum of their numbers, minus the numbers of the next player
+const (
+	mpPlayers        = 3
+	mpMovesPerPlayer = 2
+	mpBranchFactor   = 4
+)
+
+type MultiPlayerOps struct {
+	history []Move
+	rand    *rand.Rand
+}
+
+func (o *MultiPlayerOps) Reset()          {}
+func (o *MultiPlayerOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *MultiPlayerOps) BackTraverse() {
+	if len(o.history) > 0 {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *MultiPlayerOps) Players() int { return mpPlayers }
+func (o *MultiPlayerOps) Turn() int    { return len(o.history) % mpPlayers }
+
+func (o *MultiPlayerOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if len(o.history) >= mpPlayers*mpMovesPerPlayer {
+		return 0
+	}
+
+	terminal := len(o.history)+1 >= mpPlayers*mpMovesPerPlayer
+	parent.Children = make([]NodeBase[Move, *NodeStats], mpBranchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), terminal, &NodeStats{})
+	}
+	return mpBranchFactor
+}
+
+func (o *MultiPlayerOps) Rollout() Rewards {
+	played := len(o.history)
+	for len(o.history) < mpPlayers*mpMovesPerPlayer {
+		o.history = append(o.history, Move(o.rand.Intn(mpBranchFactor)))
+	}
+
+	sums := [mpPlayers]int{}
+	for i, m := range o.history {
+		sums[i%mpPlayers] += int(m)
+	}
+	o.history = o.history[:played]
+
+	const maxSum = (mpBranchFactor - 1) * mpMovesPerPlayer
+	rewards := make(Rewards, mpPlayers)
+	for p := range rewards {
+		diff := sums[p] - sums[(p+1)%mpPlayers]
+		rewards[p] = Result(diff+maxSum) / Result(2*maxSum)
+	}
+	return rewards
+}
+
+func (o *MultiPlayerOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *MultiPlayerOps) Clone() *MultiPlayerOps {
+	return &MultiPlayerOps{history: append([]Move(nil), o.history...)}
+}
+
+func TestMaxNSearch(t *testing.T) {
+	tree := NewMTCS(
+		NewMaxN[Move, *NodeStats, Rewards, *MultiPlayerOps](0.7),
+		&MultiPlayerOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetLimits(DefaultLimits().SetCycles(20000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Every player should pick the highest number
+	pv, _, _ := tree.Pv(tree.Root, BestChildMostVisits, false)
+	if len(pv) < mpPlayers {
+		t.Fatalf("Pv too short: %v", pv)
+	}
+	for i := range mpPlayers {
+		if pv[i] != mpBranchFactor-1 {
+			t.Fatalf("Expected every player to choose %d, got pv %v", mpBranchFactor-1, pv)
+		}
+	}
+
+	// Players are recorded on the nodes
+	node := tree.Root
+	for depth := range mpPlayers + 1 {
+		if !node.Expanded() {
+			break
+		}
+		node = &node.Children[0]
+		if int(node.Player) != depth%mpPlayers {
+			t.Fatalf("Expected player %d at depth %d, got %d", depth%mpPlayers, depth+1, node.Player)
+		}
+	}
+
+	// Root player's value, should be above average
+	if score := tree.RootScore(); score <= 0.5 {
+		t.Fatalf("Expected root score > 0.5, got %f", score)
+	}
+}
+
+func TestMaxNRequiresMultiPlayerOps(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic, when ops don't implement MultiPlayerGameOperations")
+		}
+	}()
+
+	NewMTCS(
+		NewMaxN[Move, *NodeStats, Rewards, *rewardsDummyOps](0.7),
+		&rewardsDummyOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+}
+
+// DummyOps returning Rewards, without the player information
+type rewardsDummyOps struct {
+	DummyOps
+}
+
+func (o *rewardsDummyOps) Rollout() Rewards { return Rewards{0.5, 0.5} }
+func (o *rewardsDummyOps) Clone() *rewardsDummyOps {
+	return &rewardsDummyOps{DummyOps: *o.DummyOps.Clone()}
+}
@@ -9,6 +9,8 @@ const (
 	ExpandingMask uint32 = 1
 	ExpandedMask  uint32 = 2
 	TerminalMask  uint32 = 4
+	ChanceMask    uint32 = 8  // children are the outcomes of a random event (see ChanceGameOperations)
+	ExhaustedMask uint32 = 16 // no more children can be added (see WideningGameOperations)
 )
 
 type NodeBase[T MoveLike, S NodeStatsLike[S]] struct {
@@ -16,7 +18,11 @@ type NodeBase[T MoveLike, S NodeStatsLike[S]] struct {
 	Move     T
 	Children []NodeBase[T, S]
 	Parent   *NodeBase[T, S]
-	Flags    uint32 // must be read/written atomically
+	Flags    uint32  // must be read/written atomically
+	Player   uint8   // player that made the move leading to this node (see MultiPlayerGameOperations)
+	proof    uint32  // game-theoretic value (see MCTS.SetSolver), must be read/written atomically
+	Prob     float32 // probability of this outcome, if the parent is a chance node
+	width    uint32  // number of children added by the progressive widening, must be read/written atomically
 }
 
 type NodeBaseDefault[T MoveLike] NodeBase[T, *NodeStats]
@@ -44,20 +50,34 @@ func (node *NodeBase[T, S]) Clone(parent *NodeBase[T, S]) *NodeBase[T, S] {
 	// TODO:
 	// Test this properly
 
+	children := node.VisibleChildren()
 	clone := &NodeBase[T, S]{
 		Move:     node.Move,
-		Children: make([]NodeBase[T, S], len(node.Children)),
+		Children: make([]NodeBase[T, S], len(children), cap(children)),
 		Parent:   parent,
 		Flags:    node.Flags,
+		Player:   node.Player,
+		proof:    atomic.LoadUint32(&node.proof),
+		Prob:     node.Prob,
 	}
 	// Create a deep copy of the children
-	for i := range node.Children {
-		clone.Children[i] = *node.Children[i].Clone(clone)
+	for i := range children {
+		clone.Children[i] = *children[i].Clone(clone)
 	}
 	clone.Stats = node.Stats.Clone()
 	return clone
 }
 
+// Children visible to the search threads. Same as Children, except for the nodes growing
+// with the progressive widening (see WideningGameOperations): during the search their
+// new children are published only here, Children is updated when the search ends.
+func (node *NodeBase[T, S]) VisibleChildren() []NodeBase[T, S] {
+	if w := int(atomic.LoadUint32(&node.width)); w > len(node.Children) && w <= cap(node.Children) {
+		return node.Children[:w]
+	}
+	return node.Children
+}
+
 // Reads the game Flags, and return wheter the stats is terminal
 func (stats *NodeBase[T, S]) Terminal() bool {
 	return atomic.LoadUint32(&stats.Flags)&TerminalMask == TerminalMask
@@ -67,6 +87,21 @@ func (stats *NodeBase[T, S]) SetFlag(flag uint32) {
 	atomic.StoreUint32(&stats.Flags, flag)
 }
 
+// Whether the node is a chance node (see ChanceGameOperations)
+func (node *NodeBase[T, S]) Chance() bool {
+	return atomic.LoadUint32(&node.Flags)&ChanceMask == ChanceMask
+}
+
+// Atomically sets the 'set' bits and clears the 'clear' bits of the flags
+func (node *NodeBase[T, S]) updateFlags(set, clear uint32) {
+	for {
+		flags := atomic.LoadUint32(&node.Flags)
+		if atomic.CompareAndSwapUint32(&node.Flags, flags, flags&^clear|set) {
+			return
+		}
+	}
+}
+
 func TerminalFlag(terminal bool) uint32 {
 	flag := uint32(0)
 	if terminal {
@@ -86,21 +121,32 @@ func (node *NodeBase[T, S]) Expanding() bool {
 }
 
 // Should be called when we want to expand this node,
-// if it's possible, sets the internal flag to 'currently expanding'
+// if it's possible, sets the internal flag to 'currently expanding'.
+// Only the thread that got true may write the Children, until FinishExpanding (or CancelExpanding),
+// other threads must not wait for it, and may read the Children only after Expanded returns true.
 func (node *NodeBase[T, S]) CanExpand() bool {
-	// TODO:
-	// This is causing the concurrent threads to deadlock in 'Expanding' loop
-	return atomic.CompareAndSwapUint32(&node.Flags, CanExpand, ExpandingMask)
+	flags := atomic.LoadUint32(&node.Flags)
+	return flags&(ExpandingMask|ExpandedMask|TerminalMask) == CanExpand &&
+		atomic.CompareAndSwapUint32(&node.Flags, flags, flags|ExpandingMask)
+}
+
+// Sets the 'currently expanding' state of the expanded node, so that a new child can be added
+// (see WideningGameOperations), returns false if other thread is doing it, or the node can't grow
+func (node *NodeBase[T, S]) startWidening() bool {
+	flags := atomic.LoadUint32(&node.Flags)
+	return flags&(ExpandingMask|ExhaustedMask|TerminalMask) == 0 && flags&ExpandedMask == ExpandedMask &&
+		atomic.CompareAndSwapUint32(&node.Flags, flags, flags|ExpandingMask)
 }
 
 // Used to undo the expanding state, if something went wrong
 // during the expansion (failed allocation of children for example)
 func (node *NodeBase[T, S]) CancelExpanding() {
-	atomic.StoreUint32(&node.Flags, CanExpand)
+	node.updateFlags(CanExpand, ExpandingMask)
 }
 
 // After successful 'CanExpand' call, use this function to set
-// the state of the node to 'expanded'
+// the state of the node to 'expanded'. The atomic flag update publishes the Children,
+// so every thread that sees Expanded also sees the fully initialized children.
 func (node *NodeBase[T, S]) FinishExpanding() {
-	atomic.StoreUint32(&node.Flags, ExpandedMask)
+	node.updateFlags(ExpandedMask, ExpandingMask)
 }
@@ -0,0 +1,260 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Root exploration noise and temperature-based move sampling, for self-play
+// Reference: https://www.nature.com/articles/nature24270 (AlphaGo Zero)
+//
+// Dirichlet noise: every search draws a noise vector eta ~ Dir(alpha) for the root's children.
+// If the stats hold priors (see PuctStatsLike), the root priors become (1 - epsilon) * P + epsilon * eta
+// for the duration of the search (the original priors are restored after it). Otherwise, the root
+// selection picks a child sampled from eta with probability epsilon, and the strategy's choice otherwise.
+//
+// BestChildSampled samples the root move from the visit distribution, proportionally to N^(1/temperature),
+// where the temperature is given by the schedule, for the number of moves made since the last Reset.
+
+type rootNoise struct {
+	alpha   float64
+	epsilon float64
+	eta     []float64 // noise of the current search
+	priors  []float64 // root priors without the noise, restored after the search
+	selects bool      // whether the noise is mixed into the root selection (no priors)
+}
+
+// Temperature of the move sampling, for the given ply (number of moves made with MakeMove since the last Reset)
+type TemperatureSchedule func(ply int) float64
+
+// 'initial' temperature for the first 'moves' plies, 'final' afterwards
+// (AlphaGo Zero used 1 for the first 30 moves, and ~0 for the rest of the game)
+func StepTemperature(initial float64, moves int, final float64) TemperatureSchedule {
+	return func(ply int) float64 {
+		if ply < moves {
+			return initial
+		}
+		return final
+	}
+}
+
+// Random generator for the noise and the move sampling, shared by every goroutine
+type sampler struct {
+	mx   sync.Mutex
+	rand *rand.Rand
+}
+
+func (s *sampler) lock() *rand.Rand {
+	s.mx.Lock()
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(SeedGeneratorFn()))
+	}
+	return s.rand
+}
+
+func (s *sampler) unlock() {
+	s.mx.Unlock()
+}
+
+// Enables the Dirichlet noise at the root, 'alpha' is the concentration (AlphaZero used 0.3 for chess,
+// 0.03 for go, roughly 10 / number of legal moves), 'epsilon' is the weight of the noise, epsilon <= 0 disables it.
+// Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetRootNoise(alpha, epsilon float64) {
+	if epsilon <= 0 || alpha <= 0 {
+		mcts.noise = nil
+		return
+	}
+	mcts.noise = &rootNoise{alpha: alpha, epsilon: min(1, epsilon)}
+}
+
+// Returns the root noise parameters, epsilon == 0 if it's disabled
+func (mcts *MCTS[T, S, R, O, A]) RootNoise() (alpha, epsilon float64) {
+	if mcts.noise == nil {
+		return 0, 0
+	}
+	return mcts.noise.alpha, mcts.noise.epsilon
+}
+
+// Sets the temperature schedule of BestChildSampled, nil means temperature 1.
+// Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetTemperature(schedule TemperatureSchedule) {
+	mcts.temperature = schedule
+}
+
+// Temperature of BestChildSampled for the current ply
+func (mcts *MCTS[T, S, R, O, A]) Temperature() float64 {
+	if mcts.temperature == nil {
+		return 1
+	}
+	return mcts.temperature(mcts.Ply())
+}
+
+// Number of moves made with MakeMove since the last Reset
+func (mcts *MCTS[T, S, R, O, A]) Ply() int {
+	return int(mcts.ply.Load())
+}
+
+// Draws the noise for the search, and mixes it into the root priors, the search must not be running
+func (mcts *MCTS[T, S, R, O, A]) applyRootNoise(root *NodeBase[T, S]) {
+	noise := mcts.noise
+	if noise == nil {
+		return
+	}
+
+	noise.eta = noise.eta[:0]
+	noise.priors = noise.priors[:0]
+	noise.selects = false
+	if !root.Expanded() || root.Chance() || len(root.VisibleChildren()) == 0 {
+		return
+	}
+
+	children := root.VisibleChildren()
+	noise.eta = append(noise.eta, make([]float64, len(children))...)
+	r := mcts.sampler.lock()
+	dirichlet(r, noise.alpha, noise.eta)
+	mcts.sampler.unlock()
+
+	if _, ok := any(children[0].Stats).(priorStats); !ok {
+		noise.selects = true
+		return
+	}
+
+	for i := range children {
+		stats := any(children[i].Stats).(priorStats)
+		prior := stats.Prior()
+		noise.priors = append(noise.priors, prior)
+		stats.SetPrior((1-noise.epsilon)*prior + noise.epsilon*noise.eta[i])
+	}
+}
+
+// Restores the root priors, after the search is done
+func (mcts *MCTS[T, S, R, O, A]) removeRootNoise(root *NodeBase[T, S]) {
+	if mcts.noise == nil || len(mcts.noise.priors) == 0 {
+		return
+	}
+
+	children := root.VisibleChildren()
+	for i, prior := range mcts.noise.priors[:min(len(children), len(mcts.noise.priors))] {
+		any(children[i].Stats).(priorStats).SetPrior(prior)
+	}
+	mcts.noise.priors = mcts.noise.priors[:0]
+}
+
+// Root child sampled from the noise with probability epsilon, nil if the strategy should choose
+func (mcts *MCTS[T, S, R, O, A]) noisyChild(root *NodeBase[T, S], isOps ISGameOperations[T, S, R, O], threadRand *rand.Rand) *NodeBase[T, S] {
+	noise := mcts.noise
+	if noise == nil || !noise.selects || threadRand.Float64() >= noise.epsilon {
+		return nil
+	}
+
+	children := root.VisibleChildren()
+	eta := noise.eta[:min(len(children), len(noise.eta))]
+	if len(eta) == 0 {
+		return nil
+	}
+
+	index := len(eta) - 1
+	u := threadRand.Float64()
+	for i, p := range eta {
+		if u -= p; u < 0 {
+			index = i
+			break
+		}
+	}
+
+	child := &children[index]
+	if child.ProvenLoss() || (isOps != nil && !isOps.IsLegal(child.Move)) {
+		return nil
+	}
+	return child
+}
+
+// Child sampled proportionally to N^(1/temperature), temperature <= 0 is the most visited child
+func (mcts *MCTS[T, S, R, O, A]) sampleChild(node *NodeBase[T, S], children []NodeBase[T, S]) *NodeBase[T, S] {
+	temperature := mcts.Temperature()
+	if temperature <= 0 {
+		return mcts.BestChild(node, BestChildMostVisits)
+	}
+
+	maxVisits := int32(0)
+	for i := range children {
+		if !children[i].ProvenLoss() {
+			maxVisits = max(maxVisits, children[i].Stats.RealVisits())
+		}
+	}
+	if maxVisits == 0 {
+		return nil
+	}
+
+	// Relative to the most visited child, so that low temperatures don't overflow
+	weights := make([]float64, len(children))
+	sum := 0.0
+	for i := range children {
+		if visits := children[i].Stats.RealVisits(); visits > 0 && !children[i].ProvenLoss() {
+			weights[i] = math.Pow(float64(visits)/float64(maxVisits), 1/temperature)
+			sum += weights[i]
+		}
+	}
+
+	r := mcts.sampler.lock()
+	u := r.Float64() * sum
+	mcts.sampler.unlock()
+
+	var chosen *NodeBase[T, S]
+	for i := range children {
+		if weights[i] == 0 {
+			continue
+		}
+		chosen = &children[i]
+		if u -= weights[i]; u < 0 {
+			break
+		}
+	}
+	return chosen
+}
+
+// Fills 'out' with a sample of the symmetric Dirichlet distribution
+func dirichlet(r *rand.Rand, alpha float64, out []float64) {
+	sum := 0.0
+	for i := range out {
+		out[i] = gammaSample(r, alpha)
+		sum += out[i]
+	}
+
+	if sum <= 0 {
+		for i := range out {
+			out[i] = 1 / float64(len(out))
+		}
+		return
+	}
+
+	for i := range out {
+		out[i] /= sum
+	}
+}
+
+// Sample of the Gamma(alpha, 1) distribution
+// Reference: https://dl.acm.org/doi/10.1145/358407.358414 (Marsaglia, Tsang, A Simple Method for Generating Gamma Variables)
+func gammaSample(r *rand.Rand, alpha float64) float64 {
+	if alpha < 1 {
+		// Gamma(alpha) = Gamma(alpha + 1) * U^(1/alpha)
+		return gammaSample(r, alpha+1) * math.Pow(r.Float64(), 1/alpha)
+	}
+
+	d := alpha - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := r.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+
+		v = v * v * v
+		u := r.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
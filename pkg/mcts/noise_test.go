@@ -0,0 +1,165 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDirichlet(t *testing.T) {
+	const samples, size = 20000, 4
+	r := rand.New(rand.NewSource(1))
+
+	for _, alpha := range []float64{0.03, 0.3, 2} {
+		mean := make([]float64, size)
+		out := make([]float64, size)
+		for range samples {
+			dirichlet(r, alpha, out)
+			sum := 0.0
+			for i, v := range out {
+				if v < 0 || math.IsNaN(v) {
+					t.Fatalf("alpha=%v: invalid sample %v", alpha, out)
+				}
+				sum += v
+				mean[i] += v / samples
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Fatalf("alpha=%v: sample doesn't sum up to 1: %v", alpha, out)
+			}
+		}
+
+		// Symmetric distribution, every component has the same mean
+		for i := range mean {
+			if math.Abs(mean[i]-1.0/size) > 0.02 {
+				t.Fatalf("alpha=%v: expected mean %v, got %v", alpha, 1.0/size, mean)
+			}
+		}
+	}
+}
+
+func TestRootNoisePriors(t *testing.T) {
+	tree := NewMTCS(
+		NewPUCT[Move, *PuctStats, Result, *PriorOps](1.5),
+		&PriorOps{},
+		MultithreadTreeParallel,
+		&PuctStats{},
+	)
+	tree.SetLimits(DefaultLimits().SetCycles(1000))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	priors := make([]float64, len(tree.Root.Children))
+	for i := range tree.Root.Children {
+		priors[i] = tree.Root.Children[i].Stats.Prior()
+	}
+
+	tree.SetRootNoise(0.3, 0.25)
+	tree.applyRootNoise(tree.Root)
+	sum, changed := 0.0, false
+	for i := range tree.Root.Children {
+		prior := tree.Root.Children[i].Stats.Prior()
+		sum += prior
+		changed = changed || prior != priors[i]
+	}
+	if !changed || math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("Expected noisy priors summing up to 1, got sum %f (changed=%v)", sum, changed)
+	}
+
+	tree.removeRootNoise(tree.Root)
+	for i := range tree.Root.Children {
+		if prior := tree.Root.Children[i].Stats.Prior(); prior != priors[i] {
+			t.Fatalf("Expected prior %f restored, got %f", priors[i], prior)
+		}
+	}
+
+	// Noise is removed after every search
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+	for i := range tree.Root.Children {
+		if prior := tree.Root.Children[i].Stats.Prior(); prior != priors[i] {
+			t.Fatalf("Expected prior %f after the search, got %f", priors[i], prior)
+		}
+	}
+}
+
+func TestRootNoiseSelection(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+
+	// Noise concentrated on a single child, always chosen at the root
+	tree.SetRootNoise(0.01, 1)
+	tree.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	noisiest := 0
+	for i, p := range tree.noise.eta {
+		if p > tree.noise.eta[noisiest] {
+			noisiest = i
+		}
+	}
+	if best := tree.BestChild(tree.Root, BestChildMostVisits); best != &tree.Root.Children[noisiest] {
+		t.Fatalf("Expected the child with the most noise (%v) to be the most visited, got %v", noisiest, best.Move)
+	}
+
+	if alpha, epsilon := tree.RootNoise(); alpha != 0.01 || epsilon != 1 {
+		t.Fatalf("Unexpected noise parameters (%v, %v)", alpha, epsilon)
+	}
+	tree.SetRootNoise(0.3, 0)
+	if _, epsilon := tree.RootNoise(); epsilon != 0 {
+		t.Fatal("Expected the noise to be disabled")
+	}
+}
+
+func TestBestChildSampled(t *testing.T) {
+	const samples = 10000
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	visits := []int32{0, 100, 300, 600}
+	tree.Root.Children = tree.Root.Children[:len(visits)]
+	for i, n := range visits {
+		tree.Root.Children[i].Stats.AddVvl(n, 0)
+	}
+
+	count := func() []int {
+		counts := make([]int, len(visits))
+		for range samples {
+			counts[tree.BestChild(tree.Root, BestChildSampled).Move]++
+		}
+		return counts
+	}
+
+	// Temperature 1, proportionally to the visits
+	counts := count()
+	for i, n := range visits {
+		if expected := float64(n) / 1000; math.Abs(float64(counts[i])/samples-expected) > 0.03 {
+			t.Fatalf("Expected child %d to be chosen %.2f of the time, got %v", i, expected, counts)
+		}
+	}
+
+	// Zero temperature after the first move, always the most visited child
+	tree.SetTemperature(StepTemperature(1, 1, 0))
+	if tree.Temperature() != 1 {
+		t.Fatalf("Expected temperature 1 at ply 0, got %v", tree.Temperature())
+	}
+	tree.ply.Store(1)
+	if counts = count(); counts[3] != samples {
+		t.Fatalf("Expected the most visited child at zero temperature, got %v", counts)
+	}
+}
+
+func TestPlyCount(t *testing.T) {
+	tree := newResettableMCTS(MultithreadTreeParallel)
+	tree.SetLimits(DefaultLimits().SetCycles(200))
+	for i := range 3 {
+		if tree.Ply() != i {
+			t.Fatalf("Expected ply %d, got %d", i, tree.Ply())
+		}
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+		tree.MakeMove(tree.BestMove())
+	}
+
+	tree.Reset(false, &NodeStats{})
+	if tree.Ply() != 0 {
+		t.Fatalf("Expected ply 0 after reset, got %d", tree.Ply())
+	}
+}
@@ -13,7 +13,8 @@ type GameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interfa
 	// Go back up 1 time in the game tree (undo previous move, which was played in traverse)
 	BackTraverse()
 	// Function to make the playout, until terminal node is reached,
-	// in case of UTTT, play random moves, until we reach draw/win/loss
+	// in case of UTTT, play random moves, until we reach draw/win/loss.
+	// Not called if the search has an Evaluator (see MCTS.SetEvaluator)
 	Rollout() R
 	// Reset game state to current internal position, called after changing
 	// position, for example using SetNotation function in engine
@@ -28,3 +29,11 @@ type RandGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] int
 	// Sets the random genertor
 	SetRand(*rand.Rand)
 }
+
+// Position hashing, required by the transposition-aware search (see MCTS.SetTranspositions)
+type HashGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	GameOperations[T, S, R, O]
+	// Hash of the current position, equal positions (including the side to move)
+	// must produce equal hashes
+	Hash() uint64
+}
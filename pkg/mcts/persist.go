@@ -0,0 +1,278 @@
+package mcts
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+// Binary format of the saved search tree (little endian):
+//
+//	header: magic "GMCT", version uint16
+//	counters: size uint32 (informative, recounted when loading), maxdepth int32, cycles uint32
+//	nodes (pre-order, starting from the root):
+//	  move (MoveCodec), flags uint32, player uint8 (since version 2), proof uint32 (since version 3),
+//	  outcome probability float32 (since version 4),
+//	  stats length uint32, stats bytes, children count uint32
+//
+// Node statistics are stored with their encoding.BinaryMarshaler implementation,
+// (NodeStats and RaveStats implement it), moves are encoded with user-provided MoveCodec.
+
+const (
+	treeFileMagic   = "GMCT"
+	treeFileVersion = uint16(4)
+
+	// Only these flags are saved, the rest describe the state of a running search
+	persistentFlags = ExpandedMask | TerminalMask | ChanceMask
+
+	// Limits of the lengths read from the file, larger ones are treated as corrupted
+	maxTreeFileStatsSize = 1 << 16
+	maxTreeFileChildren  = 1 << 24
+
+	// Children allocated up front, the rest are appended as they are read
+	maxPreallocatedChildren = 1 << 10
+)
+
+var (
+	ErrInvalidTreeFile      = errors.New("[MCTS] invalid search tree file")
+	ErrStatsNotSerializable = errors.New("[MCTS] node stats must implement encoding.BinaryMarshaler and encoding.BinaryUnmarshaler")
+)
+
+// Encodes and decodes moves of the search tree, since the move type is generic
+type MoveCodec[T MoveLike] interface {
+	WriteMove(w io.Writer, move T) error
+	ReadMove(r io.Reader) (T, error)
+}
+
+// Move codec for fixed-size move types (integers, or arrays and structs of them),
+// uses encoding/binary in little endian order. Plain int and uint moves are stored as 64-bit values.
+type BinaryMoveCodec[T MoveLike] struct{}
+
+func (BinaryMoveCodec[T]) WriteMove(w io.Writer, move T) error {
+	switch v := reflect.ValueOf(move); v.Kind() {
+	case reflect.Int:
+		return binary.Write(w, binary.LittleEndian, v.Int())
+	case reflect.Uint, reflect.Uintptr:
+		return binary.Write(w, binary.LittleEndian, v.Uint())
+	}
+	return binary.Write(w, binary.LittleEndian, move)
+}
+
+func (BinaryMoveCodec[T]) ReadMove(r io.Reader) (T, error) {
+	var move T
+	switch v := reflect.ValueOf(&move).Elem(); v.Kind() {
+	case reflect.Int:
+		var i int64
+		err := binary.Read(r, binary.LittleEndian, &i)
+		v.SetInt(i)
+		return move, err
+	case reflect.Uint, reflect.Uintptr:
+		var u uint64
+		err := binary.Read(r, binary.LittleEndian, &u)
+		v.SetUint(u)
+		return move, err
+	}
+	err := binary.Read(r, binary.LittleEndian, &move)
+	return move, err
+}
+
+// Writes the whole search tree with its counters to 'w', stops the running search.
+// Node stats must implement encoding.BinaryMarshaler.
+func (mcts *MCTS[T, S, R, O, A]) Save(w io.Writer, codec MoveCodec[T]) error {
+	// If the search is running, stop it first
+	defer mcts.acquireTree()()
+
+	if _, ok := any(mcts.Root.Stats).(encoding.BinaryMarshaler); !ok {
+		return ErrStatsNotSerializable
+	}
+
+	bw := bufio.NewWriter(w)
+	header := []any{
+		[]byte(treeFileMagic), treeFileVersion,
+		mcts.Size(), mcts.maxdepth.Load(), mcts.cycles.Load(),
+	}
+
+	for _, v := range header {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+
+	if err := saveNode(bw, mcts.Root, codec); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func saveNode[T MoveLike, S NodeStatsLike[S]](w io.Writer, node *NodeBase[T, S], codec MoveCodec[T]) error {
+	if err := codec.WriteMove(w, node.Move); err != nil {
+		return err
+	}
+
+	stats, err := any(node.Stats).(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	// Partially expanded nodes are saved as leaves
+	children := node.Children
+	if !node.Expanded() {
+		children = nil
+	}
+
+	fields := []any{node.Flags & persistentFlags, node.Player, atomic.LoadUint32(&node.proof), node.Prob, uint32(len(stats)), stats, uint32(len(children))}
+	for _, v := range fields {
+		if err := binary.Write(w, binary.LittleEndian, v); err != nil {
+			return err
+		}
+	}
+
+	for i := range children {
+		if err := saveNode(w, &children[i], codec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replaces current tree with the one read from 'r' (written by Save), stops the running search.
+// 'defaultStats' is cloned for every loaded node, and must implement encoding.BinaryUnmarshaler.
+// Game operations must be set to the position of the saved root by the caller,
+// after that, the search can be continued with SearchMultiThreaded.
+func (mcts *MCTS[T, S, R, O, A]) Load(r io.Reader, codec MoveCodec[T], defaultStats S) error {
+	// If the search is running, stop it first
+	defer mcts.acquireTree()()
+	mcts.endPondering()
+
+	if _, ok := any(defaultStats).(encoding.BinaryUnmarshaler); !ok {
+		return ErrStatsNotSerializable
+	}
+
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(treeFileMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != treeFileMagic {
+		return ErrInvalidTreeFile
+	}
+
+	var version uint16
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return ErrInvalidTreeFile
+	}
+	if version == 0 || version > treeFileVersion {
+		return fmt.Errorf("[MCTS] unsupported search tree file version %d (expected at most %d)", version, treeFileVersion)
+	}
+
+	// Size is counted again from the loaded nodes
+	var size, cycles uint32
+	var maxdepth int32
+	for _, v := range []any{&size, &maxdepth, &cycles} {
+		if err := binary.Read(br, binary.LittleEndian, v); err != nil {
+			return ErrInvalidTreeFile
+		}
+	}
+
+	root := &NodeBase[T, S]{}
+	if err := loadNode(br, root, codec, defaultStats, version); err != nil {
+		return err
+	}
+
+	// Old tree is replaced, give its nodes back to the pool
+	if mcts.usePool() {
+		mcts.pool.Release(mcts.Root)
+	}
+
+	mcts.Root = root
+	mcts.size.Store(uint32(countTreeNodes(root)))
+	mcts.maxdepth.Store(maxdepth)
+	mcts.cycles.Store(cycles)
+	mcts.retainBestSequence(nil, nil)
+
+	// Loaded nodes have their own statistics
+	if mcts.table != nil {
+		mcts.table.Clear()
+	}
+	return nil
+}
+
+func loadNode[T MoveLike, S NodeStatsLike[S]](r io.Reader, node *NodeBase[T, S], codec MoveCodec[T], defaultStats S, version uint16) error {
+	move, err := codec.ReadMove(r)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidTreeFile, err)
+	}
+
+	var flags, proof, statsLen, childCount uint32
+	var player uint8
+	var prob float32
+	if err := binary.Read(r, binary.LittleEndian, &flags); err != nil {
+		return ErrInvalidTreeFile
+	}
+	if version >= 2 {
+		if err := binary.Read(r, binary.LittleEndian, &player); err != nil {
+			return ErrInvalidTreeFile
+		}
+	}
+	if version >= 3 {
+		if err := binary.Read(r, binary.LittleEndian, &proof); err != nil {
+			return ErrInvalidTreeFile
+		}
+	}
+	if version >= 4 {
+		if err := binary.Read(r, binary.LittleEndian, &prob); err != nil {
+			return ErrInvalidTreeFile
+		}
+	}
+	if err := binary.Read(r, binary.LittleEndian, &statsLen); err != nil || statsLen > maxTreeFileStatsSize {
+		return ErrInvalidTreeFile
+	}
+
+	buf := make([]byte, statsLen)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return ErrInvalidTreeFile
+	}
+
+	stats := defaultStats.Clone()
+	if err := any(stats).(encoding.BinaryUnmarshaler).UnmarshalBinary(buf); err != nil {
+		return err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &childCount); err != nil || childCount > maxTreeFileChildren {
+		return ErrInvalidTreeFile
+	}
+
+	node.Move = move
+	node.Stats = stats
+	node.Flags = flags & persistentFlags
+	node.Player = player
+	node.proof = proof
+	node.Prob = prob
+
+	if childCount == 0 {
+		// Nothing to search there
+		node.Flags &^= ExpandedMask
+		return nil
+	}
+
+	// The count may be corrupted, so the memory grows only with the children actually read
+	node.Children = make([]NodeBase[T, S], 0, min(childCount, maxPreallocatedChildren))
+	for range childCount {
+		node.Children = append(node.Children, NodeBase[T, S]{})
+		if err := loadNode(r, &node.Children[len(node.Children)-1], codec, defaultStats, version); err != nil {
+			return err
+		}
+	}
+
+	// Children could be moved by append
+	for i := range node.Children {
+		child := &node.Children[i]
+		child.Parent = node
+		for j := range child.Children {
+			child.Children[j].Parent = child
+		}
+	}
+	return nil
+}
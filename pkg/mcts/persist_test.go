@@ -0,0 +1,130 @@
+package mcts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestSaveLoadTree(t *testing.T) {
+	mcts := GetDummyMCTS()
+
+	buf := &bytes.Buffer{}
+	if err := mcts.Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := NewDummyMCTS(MultithreadTreeParallel)
+	if err := loaded.Load(buf, BinaryMoveCodec[Move]{}, &NodeStats{}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !deepCompare(mcts.Root, loaded.Root) {
+		t.Fatal("Loaded tree differs from the saved one")
+	}
+
+	if loaded.Size() != mcts.Size() || loaded.Cycles() != mcts.Cycles() || loaded.MaxDepth() != mcts.MaxDepth() {
+		t.Fatalf("Counters differ: size %d/%d cycles %d/%d maxdepth %d/%d",
+			loaded.Size(), mcts.Size(), loaded.Cycles(), mcts.Cycles(), loaded.MaxDepth(), mcts.MaxDepth())
+	}
+
+	for i := range loaded.Root.Children {
+		child := &loaded.Root.Children[i]
+		if child.Parent != loaded.Root {
+			t.Fatal("Parent pointers not restored")
+		}
+		for j := range child.Children {
+			if child.Children[j].Parent != child {
+				t.Fatal("Parent pointers not restored")
+			}
+		}
+	}
+
+	// Continue the search on the loaded tree
+	visits := loaded.Root.Stats.N()
+	loaded.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(2))
+	loaded.SearchMultiThreaded()
+	loaded.Synchronize()
+
+	if loaded.Root.Stats.N() <= visits {
+		t.Fatalf("Search didn't continue on the loaded tree: %d <= %d", loaded.Root.Stats.N(), visits)
+	}
+}
+
+func TestLoadInvalidTree(t *testing.T) {
+	mcts := NewDummyMCTS(MultithreadTreeParallel)
+	err := mcts.Load(bytes.NewReader([]byte("not a tree")), BinaryMoveCodec[Move]{}, &NodeStats{})
+	if !errors.Is(err, ErrInvalidTreeFile) {
+		t.Fatalf("Expected ErrInvalidTreeFile, got %v", err)
+	}
+
+	// Truncated file
+	buf := &bytes.Buffer{}
+	if err := GetDummyMCTS().Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	err = mcts.Load(bytes.NewReader(buf.Bytes()[:buf.Len()/2]), BinaryMoveCodec[Move]{}, &NodeStats{})
+	if !errors.Is(err, ErrInvalidTreeFile) {
+		t.Fatalf("Expected ErrInvalidTreeFile for truncated file, got %v", err)
+	}
+}
+
+func TestLoadCorruptedLengths(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := GetDummyMCTS().Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Root: header (18 bytes), move (8), flags (4), player (1), proof (4), probability (4), stats length
+	const statsLenOffset = 18 + 8 + 4 + 1 + 4 + 4
+	statsLen := binary.LittleEndian.Uint32(buf.Bytes()[statsLenOffset:])
+	childCountOffset := statsLenOffset + 4 + int(statsLen)
+
+	corrupt := func(offset int, value uint32) []byte {
+		data := bytes.Clone(buf.Bytes())
+		binary.LittleEndian.PutUint32(data[offset:], value)
+		return data
+	}
+
+	cases := map[string][]byte{
+		"huge stats length":        corrupt(statsLenOffset, 0xFFFFFFFF),
+		"huge children count":      corrupt(childCountOffset, 0xFFFFFFFF),
+		"more children than saved": corrupt(childCountOffset, maxTreeFileChildren),
+	}
+
+	for name, data := range cases {
+		mcts := NewDummyMCTS(MultithreadTreeParallel)
+		if err := mcts.Load(bytes.NewReader(data), BinaryMoveCodec[Move]{}, &NodeStats{}); !errors.Is(err, ErrInvalidTreeFile) {
+			t.Fatalf("%s: expected ErrInvalidTreeFile, got %v", name, err)
+		}
+	}
+}
+
+func TestLoadRecountsAndReleases(t *testing.T) {
+	buf := &bytes.Buffer{}
+	saved := GetDummyMCTS()
+	if err := saved.Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Header size (after the magic and the version) doesn't match the saved nodes
+	data := bytes.Clone(buf.Bytes())
+	binary.LittleEndian.PutUint32(data[len(treeFileMagic)+2:], saved.Size()+1000)
+
+	pool := NewNodePool[Move, NodeStats](64)
+	tree := newPooledMCTS(pool)
+	tree.SetLimits(DefaultLimits().SetCycles(200).SetThreads(1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if err := tree.Load(bytes.NewReader(data), BinaryMoveCodec[Move]{}, &NodeStats{}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) || tree.Size() != saved.Size() {
+		t.Fatalf("Expected the size %d of the loaded nodes, got %d", saved.Size(), tree.Size())
+	}
+	if len(pool.freeStats) == 0 || len(pool.freeNodes) == 0 {
+		t.Fatal("Expected the replaced tree to be released to the pool")
+	}
+}
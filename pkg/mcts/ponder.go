@@ -0,0 +1,76 @@
+package mcts
+
+import "sync/atomic"
+
+// Pondering - searching on the opponent's time
+//
+// After our move was made, Ponder keeps searching the tree (now rooted at the opponent's
+// position) with no clock, time, cycles or depth limits. When the opponent moves, MakeMove promotes
+// that subtree as usual, and restores the normal limits. If the opponent played the reply we
+// expected (the best one, see BestChildDefault), it's a 'ponderhit': the search continues right away with
+// the normal limits, keeping everything searched so far, so the caller should only Synchronize.
+// Otherwise the search stays stopped, and the caller starts a new one.
+
+type ponderState struct {
+	active atomic.Bool
+	hit    atomic.Bool
+	limits *Limits // limits of the normal search, restored when pondering ends
+}
+
+// Limits used while pondering, the memory limit (and pruning) is still respected
+func ponderLimits(limits *Limits) *Limits {
+	ponder := *limits
+	ponder.Depth = DefaultDepthLimit
+	ponder.Cycles = DefaultCyclesLimit
+	ponder.Movetime = DefaultMovetimeLimit
+	ponder.Clock = Clock{}
+	ponder.Infinite = limits.ByteSize == DefaultByteSizeLimit
+	return &ponder
+}
+
+// Starts searching the current root on the opponent's time, until the opponent's move is made
+// (see MakeMove) or StopPondering is called. Limits must not be changed while pondering.
+// If the search is running, stops it first, safe to call from any goroutine.
+func (mcts *MCTS[T, S, R, O, A]) Ponder() {
+	defer mcts.acquireTree()()
+
+	mcts.endPondering()
+	if mcts.Root.Terminal() {
+		return
+	}
+
+	mcts.ponder.limits = mcts.Limiter.Limits()
+	mcts.Limiter.SetLimits(ponderLimits(mcts.ponder.limits))
+	mcts.ponder.active.Store(true)
+	mcts.ponder.hit.Store(false)
+	mcts.startSearch()
+}
+
+// Stops pondering and restores the normal limits, the tree is kept.
+// Safe to call from any goroutine.
+func (mcts *MCTS[T, S, R, O, A]) StopPondering() {
+	defer mcts.acquireTree()()
+	mcts.endPondering()
+}
+
+// Whether the tree is pondering, that is Ponder was called, and the opponent didn't move yet
+func (mcts *MCTS[T, S, R, O, A]) Pondering() bool {
+	return mcts.ponder.active.Load()
+}
+
+// Whether the last move made with MakeMove was the expected reply, while pondering
+func (mcts *MCTS[T, S, R, O, A]) PonderHit() bool {
+	return mcts.ponder.hit.Load()
+}
+
+// Leaves the pondering mode, the lifecycle mutex must be held, and the search stopped.
+// Returns true, if the tree was pondering.
+func (mcts *MCTS[T, S, R, O, A]) endPondering() bool {
+	if !mcts.ponder.active.Swap(false) {
+		return false
+	}
+
+	mcts.Limiter.SetLimits(mcts.ponder.limits)
+	mcts.ponder.limits = nil
+	return true
+}
@@ -0,0 +1,118 @@
+package mcts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPonderHit(t *testing.T) {
+	tree := newResettableMCTS(MultithreadTreeParallel)
+	limits := DefaultLimits().SetCycles(2000).SetThreads(2)
+	tree.SetLimits(limits)
+
+	tree.Ponder()
+	if !tree.Pondering() || !tree.IsSearching() {
+		t.Fatal("Expected the tree to be pondering")
+	}
+	if tree.Limits() == limits || !tree.Limits().Infinite {
+		t.Fatalf("Expected infinite limits while pondering, got %v", tree.Limits())
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Opponent plays the expected reply
+	expected := tree.BestChild(tree.Root, BestChildMostVisits)
+	if expected == nil {
+		t.Fatal("Expected the ponder search to visit the root's children")
+	}
+	move := expected.Move
+
+	if !tree.MakeMove(move) {
+		t.Fatal("Expected to make the pondered move")
+	}
+	if !tree.PonderHit() || tree.Pondering() {
+		t.Fatal("Expected a ponderhit")
+	}
+	if tree.Limits() != limits {
+		t.Fatalf("Expected the normal limits after the ponderhit, got %v", tree.Limits())
+	}
+
+	// Search continues with the normal limits
+	visits := tree.Root.Stats.N()
+	if visits == 0 {
+		t.Fatal("Expected the subtree searched while pondering to be kept")
+	}
+	if !tree.IsSearching() {
+		t.Fatal("Expected the search to continue after the ponderhit")
+	}
+	tree.Synchronize()
+
+	if tree.StopReason() != StopCycles {
+		t.Fatalf("Expected the search to stop on the cycles limit, got %s", tree.StopReason())
+	}
+	if n := tree.Root.Stats.N(); n < visits+int32(limits.Cycles) {
+		t.Fatalf("Expected at least %d root visits, got %d", visits+int32(limits.Cycles), n)
+	}
+	checkVirtualLoss(t, tree.Root)
+}
+
+func TestPonderMiss(t *testing.T) {
+	tree := newResettableMCTS(MultithreadTreeParallel)
+	limits := DefaultLimits().SetMovetime(50).SetThreads(2)
+	tree.SetLimits(limits)
+
+	tree.Ponder()
+	time.Sleep(20 * time.Millisecond)
+
+	// Opponent plays anything but the expected reply
+	var move Move
+	expected := tree.BestChild(tree.Root, BestChildMostVisits).Move
+	for i := range tree.Root.Children {
+		if tree.Root.Children[i].Move != expected {
+			move = tree.Root.Children[i].Move
+			break
+		}
+	}
+
+	if !tree.MakeMove(move) {
+		t.Fatal("Expected to make the move")
+	}
+	if tree.PonderHit() || tree.Pondering() || tree.IsSearching() {
+		t.Fatal("Expected the search to stop after a ponder miss")
+	}
+	if tree.Limits() != limits {
+		t.Fatalf("Expected the normal limits after the ponder miss, got %v", tree.Limits())
+	}
+}
+
+func TestStopPondering(t *testing.T) {
+	tree := newResettableMCTS(MultithreadRootParallel)
+	limits := DefaultLimits().SetByteSize(1 << 20).SetThreads(4)
+	tree.SetLimits(limits)
+
+	// Memory limit is kept while pondering
+	tree.Ponder()
+	if tree.Limits().Infinite || tree.Limits().ByteSize != limits.ByteSize {
+		t.Fatalf("Expected the memory limit while pondering, got %v", tree.Limits())
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	tree.StopPondering()
+	if tree.Pondering() || tree.IsSearching() {
+		t.Fatal("Expected the pondering to stop")
+	}
+	if tree.Limits() != limits {
+		t.Fatalf("Expected the normal limits, got %v", tree.Limits())
+	}
+	if tree.Root.Stats.N() == 0 {
+		t.Fatal("Expected the tree to be kept")
+	}
+
+	// Starting a normal search ends the pondering as well
+	tree.Ponder()
+	tree.SearchMultiThreaded()
+	if tree.Pondering() || tree.Limits() != limits {
+		t.Fatal("Expected the search to end the pondering")
+	}
+	tree.Stop()
+	tree.Synchronize()
+}
@@ -0,0 +1,173 @@
+package mcts
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// Block allocator of the tree nodes and their statistics, reducing the GC pressure of big trees.
+// Instead of allocating a new slice (and a stats object for every child) in each ExpandNode call,
+// children are carved out of big preallocated blocks. Subtrees released by MakeMove and Reset
+// are returned to the pool, and reused by the next expansions.
+//
+// Usage in ExpandNode:
+//
+//	parent.Children = pool.Children(len(moves))
+//	for i, m := range moves {
+//		parent.Children[i] = *mcts.NewBaseNode(parent, m, terminal, pool.Stats())
+//	}
+//
+// Nodes of the released subtrees are reused, so don't keep any pointers to them after MakeMove or Reset.
+
+// Allocator of the tree nodes, the tree returns the released subtrees to it (see MCTS.SetNodePool)
+type NodeAllocator[T MoveLike, S NodeStatsLike[S]] interface {
+	// Takes back the children of 'node', with their subtrees and statistics
+	Release(node *NodeBase[T, S])
+	// Bytes allocated by the allocator
+	MemoryUsage() uint32
+}
+
+// Stats of the NodePool, S must be a pointer to E (for example *NodeStats)
+type PooledStats[E any, S any] interface {
+	*E
+	NodeStatsLike[S]
+}
+
+type NodePool[T MoveLike, E any, S PooledStats[E, S]] struct {
+	mx        sync.Mutex
+	blockSize int
+	// Unused part of the current blocks
+	nodes []NodeBase[T, S]
+	stats []E
+	// Released children slices (by capacity) and statistics
+	freeNodes map[int][][]NodeBase[T, S]
+	freeStats []S
+	allocated uint32
+}
+
+// Creates a pool allocating 'blockSize' nodes (and stats) at once
+func NewNodePool[T MoveLike, E any, S PooledStats[E, S]](blockSize int) *NodePool[T, E, S] {
+	return &NodePool[T, E, S]{
+		blockSize: max(1, blockSize),
+		freeNodes: make(map[int][][]NodeBase[T, S]),
+	}
+}
+
+// Returns zeroed slice of 'n' nodes, safe to use by multiple search threads
+func (p *NodePool[T, E, S]) Children(n int) []NodeBase[T, S] {
+	if n <= 0 {
+		return nil
+	}
+
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if free := p.freeNodes[n]; len(free) > 0 {
+		p.freeNodes[n] = free[:len(free)-1]
+		return free[len(free)-1]
+	}
+
+	// Doesn't fit in a block
+	if n > p.blockSize {
+		p.allocated += uint32(n) * uint32(unsafe.Sizeof(NodeBase[T, S]{}))
+		return make([]NodeBase[T, S], n)
+	}
+
+	// Rest of the current block is wasted
+	if len(p.nodes) < n {
+		p.nodes = make([]NodeBase[T, S], p.blockSize)
+		p.allocated += uint32(p.blockSize) * uint32(unsafe.Sizeof(NodeBase[T, S]{}))
+	}
+
+	children := p.nodes[:n:n]
+	p.nodes = p.nodes[n:]
+	return children
+}
+
+// Returns zeroed node statistics, safe to use by multiple search threads
+func (p *NodePool[T, E, S]) Stats() S {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	if n := len(p.freeStats); n > 0 {
+		stats := p.freeStats[n-1]
+		p.freeStats = p.freeStats[:n-1]
+		return stats
+	}
+
+	if len(p.stats) == 0 {
+		p.stats = make([]E, p.blockSize)
+		p.allocated += uint32(p.blockSize) * uint32(unsafe.Sizeof(*new(E)))
+	}
+
+	stats := S(&p.stats[0])
+	p.stats = p.stats[1:]
+	return stats
+}
+
+// Takes back the children of 'node' with their subtrees and statistics, 'node' becomes a leaf.
+// Must not be called during the search.
+func (p *NodePool[T, E, S]) Release(node *NodeBase[T, S]) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.release(node)
+}
+
+func (p *NodePool[T, E, S]) release(node *NodeBase[T, S]) {
+	for i := range node.Children {
+		child := &node.Children[i]
+		p.release(child)
+
+		if stats := (*E)(child.Stats); stats != nil {
+			*stats = *new(E)
+			p.freeStats = append(p.freeStats, child.Stats)
+		}
+	}
+
+	if n := cap(node.Children); n > 0 {
+		children := node.Children[:n]
+		clear(children)
+		p.freeNodes[n] = append(p.freeNodes[n], children)
+	}
+	node.Children = nil
+	node.width = 0
+}
+
+// Bytes allocated by the pool (used and free)
+func (p *NodePool[T, E, S]) MemoryUsage() uint32 {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.allocated
+}
+
+// Sets the allocator, that gets the subtrees released by MakeMove and Reset, nil disables it.
+// Released subtrees aren't returned with transpositions enabled, since the statistics are shared then.
+// Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetNodePool(pool NodeAllocator[T, S]) {
+	mcts.pool = pool
+}
+
+func (mcts *MCTS[T, S, R, O, A]) NodePool() NodeAllocator[T, S] {
+	return mcts.pool
+}
+
+// Whether the released subtrees should be returned to the pool
+func (mcts *MCTS[T, S, R, O, A]) usePool() bool {
+	return mcts.pool != nil && mcts.table == nil
+}
+
+// Moves the 'child' out of its parent's Children, so that the parent's subtree can be released
+func detachNode[T MoveLike, S NodeStatsLike[S]](child *NodeBase[T, S]) *NodeBase[T, S] {
+	node := &NodeBase[T, S]{}
+	*node = *child
+	node.Parent = nil
+	for i := range node.Children {
+		node.Children[i].Parent = node
+	}
+
+	// Now owned by the detached node
+	var zero S
+	child.Children = nil
+	child.Stats = zero
+	return node
+}
@@ -0,0 +1,122 @@
+package mcts
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// DummyOps allocating the nodes from the pool
+type PooledOps struct {
+	DummyOps
+	pool *NodePool[Move, NodeStats, *NodeStats]
+}
+
+func (o *PooledOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if o.depth >= 8 {
+		return 0
+	}
+
+	parent.Children = o.pool.Children(branchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), false, o.pool.Stats())
+	}
+	return branchFactor
+}
+
+func (o *PooledOps) Clone() *PooledOps {
+	return &PooledOps{DummyOps: *o.DummyOps.Clone(), pool: o.pool}
+}
+
+func newPooledMCTS(pool *NodePool[Move, NodeStats, *NodeStats]) *MCTS[Move, *NodeStats, Result, *PooledOps, *UCB1[Move, *NodeStats, Result, *PooledOps]] {
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *PooledOps](0.45),
+		&PooledOps{pool: pool},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetNodePool(pool)
+	return tree
+}
+
+func TestNodePoolAllocation(t *testing.T) {
+	const blockSize = 100
+	pool := NewNodePool[Move, NodeStats](blockSize)
+
+	children := pool.Children(30)
+	if len(children) != 30 || cap(children) != 30 {
+		t.Fatalf("Expected 30 children, got len %d cap %d", len(children), cap(children))
+	}
+
+	// Carved from the same block
+	other := pool.Children(30)
+	nodeSize := unsafe.Sizeof(NodeBase[Move, *NodeStats]{})
+	if unsafe.Pointer(&other[0]) != unsafe.Add(unsafe.Pointer(&children[0]), 30*nodeSize) {
+		t.Fatal("Expected consecutive slices from the same block")
+	}
+
+	blockBytes := uint32(blockSize * nodeSize)
+	if usage := pool.MemoryUsage(); usage != blockBytes {
+		t.Fatalf("Expected %d bytes, got %d", blockBytes, usage)
+	}
+
+	stats := pool.Stats()
+	stats.AddQ(1)
+	stats.AddVvl(1, 0)
+
+	// Released stats and slices are zeroed and reused
+	node := &NodeBase[Move, *NodeStats]{Children: children}
+	children[0].Stats = stats
+	pool.Release(node)
+
+	if node.Children != nil {
+		t.Fatal("Released node should have no children")
+	}
+	if again := pool.Stats(); again != stats || again.N() != 0 || again.Q() != 0 {
+		t.Fatal("Expected the released stats to be reused and zeroed")
+	}
+	if again := pool.Children(30); &again[0] != &children[0] || again[0].Stats != nil {
+		t.Fatal("Expected the released slice to be reused and zeroed")
+	}
+}
+
+func TestNodePoolTree(t *testing.T) {
+	pool := NewNodePool[Move, NodeStats](1024)
+	tree := newPooledMCTS(pool)
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if usage := tree.MemoryUsage(); usage < pool.MemoryUsage() || pool.MemoryUsage() == 0 {
+		t.Fatalf("Memory usage %d doesn't include the pool (%d)", usage, pool.MemoryUsage())
+	}
+
+	// Subtree of the new root is kept
+	best := tree.BestChild(tree.Root, BestChildMostVisits)
+	visits, children := best.Stats.N(), len(best.Children)
+	if !tree.MakeMove(best.Move) {
+		t.Fatal("MakeMove failed")
+	}
+
+	if tree.Root.Stats.N() != visits || len(tree.Root.Children) != children || tree.Root.Parent != nil {
+		t.Fatal("New root lost its subtree")
+	}
+	for i := range tree.Root.Children {
+		if tree.Root.Children[i].Parent != tree.Root {
+			t.Fatal("Children of the new root are not linked to it")
+		}
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+
+	// Released nodes are reused, so the pool doesn't grow while the tree is rebuilt
+	allocated := pool.MemoryUsage()
+	tree.Reset(false, &NodeStats{})
+	tree.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if pool.MemoryUsage() != allocated {
+		t.Fatalf("Expected the pool to reuse released nodes, allocated %d -> %d", allocated, pool.MemoryUsage())
+	}
+}
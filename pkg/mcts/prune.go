@@ -0,0 +1,117 @@
+package mcts
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// Memory-bounded search (see Limits.SetPrune)
+//
+// When the tree reaches the memory limit, instead of freezing it, the search frees
+// the least valuable subtrees: the expanded nodes with the fewest visits, outside of the
+// principal variation. Freed nodes become leaves again, keeping their statistics, so they
+// can be expanded later, if the search comes back to them. Pruning runs with every other
+// search thread paused, and frees the nodes until the tree takes at most 'pruneTarget'
+// of the memory limit, so that it doesn't run on every cycle.
+// With transpositions enabled, the table entries of the freed positions are removed as well.
+
+const pruneTarget = 0.75
+
+type pruneCandidate[T MoveLike, S NodeStatsLike[S]] struct {
+	node   *NodeBase[T, S]
+	visits int32
+	depth  int
+}
+
+// Whether the tree should be pruned to stay under the memory limit
+func (mcts *MCTS[T, S, R, O, A]) shouldPrune() bool {
+	return mcts.Limiter.Limits().Prune && mcts.limiterSize() >= mcts.Limiter.MaxSize()
+}
+
+// Frees the least valuable subtrees, until the tree takes at most 'pruneTarget' of the memory limit.
+// Waits for the other search threads to finish their cycles, and blocks them until done.
+func (mcts *MCTS[T, S, R, O, A]) prune() {
+	mcts.pruneMx.Lock()
+	defer mcts.pruneMx.Unlock()
+
+	// Other thread might have pruned the tree already
+	if !mcts.shouldPrune() {
+		return
+	}
+
+	// Root-parallel threads have their own trees, but they share the size counter
+	roots := mcts.roots
+	if len(roots) == 0 {
+		roots = []*NodeBase[T, S]{mcts.Root}
+	}
+
+	var candidates []pruneCandidate[T, S]
+	for i, root := range roots {
+		if !slices.Contains(roots[:i], root) {
+			// Every thread is paused, the widened children can be moved to Children
+			if mcts.widening != nil {
+				commitChildren(root)
+			}
+			candidates = mcts.pruneCandidates(root, candidates)
+		}
+	}
+
+	// Least visited first, descendants before their ancestors
+	slices.SortFunc(candidates, func(a, b pruneCandidate[T, S]) int {
+		if a.visits != b.visits {
+			return int(a.visits - b.visits)
+		}
+		return b.depth - a.depth
+	})
+
+	target := uint32(float64(mcts.Limiter.MaxSize()) * pruneTarget)
+	for i := 0; i < len(candidates) && mcts.limiterSize() > target; i++ {
+		// Already freed along with its ancestor
+		if !candidates[i].node.Expanded() {
+			continue
+		}
+		if freed := mcts.collapse(candidates[i].node); freed > 0 {
+			mcts.size.Add(^(freed - 1))
+		}
+	}
+}
+
+// Appends the expanded nodes of the 'root' subtree, that can be freed (everything outside the principal variation)
+func (mcts *MCTS[T, S, R, O, A]) pruneCandidates(root *NodeBase[T, S], candidates []pruneCandidate[T, S]) []pruneCandidate[T, S] {
+	pv, _ := mcts.PvNodes(root, BestChildDefault, true)
+
+	var walk func(node *NodeBase[T, S], depth int)
+	walk = func(node *NodeBase[T, S], depth int) {
+		for i := range node.Children {
+			child := &node.Children[i]
+			if !child.Expanded() {
+				continue
+			}
+
+			if !slices.Contains(pv, child) {
+				candidates = append(candidates, pruneCandidate[T, S]{node: child, visits: child.Stats.RealVisits(), depth: depth + 1})
+			}
+			walk(child, depth+1)
+		}
+	}
+
+	walk(root, 0)
+	return candidates
+}
+
+// Frees the subtree of the 'node', making it a leaf again, returns the number of freed nodes
+func (mcts *MCTS[T, S, R, O, A]) collapse(node *NodeBase[T, S]) uint32 {
+	freed := uint32(countTreeNodes(node) - 1)
+	if mcts.table != nil {
+		mcts.table.Evict(node)
+	}
+	if mcts.usePool() {
+		mcts.pool.Release(node)
+	} else {
+		node.Children = nil
+	}
+
+	atomic.StoreUint32(&node.width, 0)
+	node.updateFlags(0, ExpandedMask|ExhaustedMask)
+	return freed
+}
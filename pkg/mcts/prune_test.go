@@ -0,0 +1,127 @@
+package mcts
+
+import (
+	"testing"
+	"unsafe"
+)
+
+const pruneTestNodes = 2000
+
+func pruneTestLimits(cycles uint32, threads int) *Limits {
+	nodeSize := int64(unsafe.Sizeof(NodeBase[Move, *NodeStats]{}))
+	return DefaultLimits().
+		SetCycles(cycles).
+		SetThreads(threads).
+		SetByteSize(pruneTestNodes * nodeSize).
+		SetPrune(true)
+}
+
+func checkPrunedTree[O GameOperations[Move, *NodeStats, Result, O], A StrategyLike[Move, *NodeStats, Result, O]](
+	t *testing.T, tree *MCTS[Move, *NodeStats, Result, O, A], cycles uint32, threads int) {
+	t.Helper()
+
+	// Threads may expand a few nodes each, before the tree is pruned
+	if maxSize := tree.Limiter.MaxSize() + uint32(threads*branchFactor); tree.Size() > maxSize {
+		t.Fatalf("Tree has %d nodes, expected at most %d", tree.Size(), maxSize)
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+	if reason := tree.StopReason(); reason&StopMemory != 0 || reason&StopCycles == 0 {
+		t.Fatalf("Expected the search to stop on cycles, got %s", reason)
+	}
+	// Other threads may finish their last cycles after the limit
+	if n := tree.Root.Stats.N(); n < int32(cycles) || n >= int32(cycles)+int32(threads) {
+		t.Fatalf("Expected %d root visits, got %d", cycles, n)
+	}
+
+	// Principal variation is kept
+	if pv, _, _ := tree.Pv(tree.Root, BestChildMostVisits, false); len(pv) < 2 {
+		t.Fatalf("Expected the pv to survive the pruning, got %v", pv)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	const cycles = 20000
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	tree.SetLimits(pruneTestLimits(cycles, 1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	checkPrunedTree(t, &tree.MCTS, cycles, 1)
+}
+
+func TestPruneMultiThreaded(t *testing.T) {
+	const cycles, threads = 20000, 4
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	tree.SetLimits(pruneTestLimits(cycles, threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	checkPrunedTree(t, &tree.MCTS, cycles, threads)
+}
+
+func TestPruneNodePool(t *testing.T) {
+	const cycles = 20000
+	pool := NewNodePool[Move, NodeStats](256)
+	tree := newPooledMCTS(pool)
+	tree.SetLimits(pruneTestLimits(cycles, 1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	checkPrunedTree(t, tree, cycles, 1)
+
+	// Freed subtrees are reused by the new expansions
+	nodeSize := uint32(unsafe.Sizeof(NodeBase[Move, *NodeStats]{}))
+	if usage := pool.MemoryUsage() / nodeSize; usage > 2*pruneTestNodes {
+		t.Fatalf("Expected the pool to reuse freed nodes, allocated %d nodes", usage)
+	}
+}
+
+func TestPruneDisabled(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	tree.SetLimits(pruneTestLimits(20000, 1).SetPrune(false))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Tree stops growing instead
+	if tree.Limiter.Expand() {
+		t.Fatal("Expected the expansion to be disabled after reaching the memory limit")
+	}
+	if tree.Size() < tree.Limiter.MaxSize() {
+		t.Fatalf("Expected the tree to reach the memory limit, got %d nodes", tree.Size())
+	}
+}
+
+func TestPruneTranspositions(t *testing.T) {
+	const cycles, threads = 20000, 2
+	tree := newTransMCTS()
+	tree.SetLimits(pruneTestLimits(cycles, threads).SetByteSize(500 * int64(unsafe.Sizeof(NodeBase[Move, *NodeStats]{}))))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Table counts towards the limit, pruning must free its entries too
+	if maxSize := tree.Limiter.MaxSize() + uint32(threads*transBranchFactor); tree.limiterSize() > maxSize {
+		t.Fatalf("Tree with the table takes %d nodes, expected at most %d", tree.limiterSize(), maxSize)
+	}
+	if reason := tree.StopReason(); reason&StopMemory != 0 || reason&StopCycles == 0 {
+		t.Fatalf("Expected the search to stop on cycles, got %s", reason)
+	}
+
+	// Every entry is owned by a node of the tree
+	owned := 0
+	var walk func(node *NodeBase[Move, *NodeStats])
+	walk = func(node *NodeBase[Move, *NodeStats]) {
+		if tree.Transpositions().Owner(node.Stats) == node {
+			owned++
+		}
+		for i := range node.Children {
+			walk(&node.Children[i])
+		}
+	}
+	walk(tree.Root)
+
+	if size := int(tree.Transpositions().Size()); size != owned || size == 0 {
+		t.Fatalf("Expected %d positions owned by the tree, got %d in the table", owned, size)
+	}
+}
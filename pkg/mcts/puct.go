@@ -0,0 +1,240 @@
+package mcts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// Predictor + Upper Confidence bounds applied to Trees (PUCT) selection policy
+// Reference: https://www.nature.com/articles/nature24270 (AlphaGo Zero)
+// Every child holds a prior probability P of being the best move, given either by
+// ExpandNode (see NewPuctStats) or by the PriorGameOperations, and the selection
+// picks the child maximizing: Q/N + c_puct * P * sqrt(N_parent) / (1 + N)
+
+type PuctStatsLike[S any] interface {
+	NodeStatsLike[S]
+
+	// Prior probability of choosing this node's move
+	Prior() float64
+	SetPrior(float64)
+}
+
+// Node statistics with the move prior
+type PuctStats struct {
+	NodeStats
+
+	// float64 bits of the prior, stored as uint64 for atomic access
+	prior uint64
+}
+
+func DefaultPuctStats() *PuctStats {
+	return &PuctStats{}
+}
+
+// Creates stats with given prior, use it in ExpandNode if the priors are known there
+func NewPuctStats(prior float64) *PuctStats {
+	return &PuctStats{prior: math.Float64bits(prior)}
+}
+
+func (p *PuctStats) Clone() *PuctStats {
+	return &PuctStats{
+		NodeStats: NodeStats{
+			q:           atomic.LoadUint64(&p.q),
+			n:           atomic.LoadInt32(&p.n),
+			virtualLoss: atomic.LoadInt32(&p.virtualLoss),
+		},
+		prior: atomic.LoadUint64(&p.prior),
+	}
+}
+
+func (p *PuctStats) Prior() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&p.prior))
+}
+
+func (p *PuctStats) SetPrior(prior float64) {
+	atomic.StoreUint64(&p.prior, math.Float64bits(prior))
+}
+
+// Encodes the node counters and the prior, implements encoding.BinaryMarshaler
+func (p *PuctStats) MarshalBinary() ([]byte, error) {
+	buf := p.NodeStats.appendBinary(make([]byte, 0, nodeStatsBinarySize+8))
+	return binary.LittleEndian.AppendUint64(buf, atomic.LoadUint64(&p.prior)), nil
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (p *PuctStats) UnmarshalBinary(data []byte) error {
+	if len(data) < nodeStatsBinarySize+8 {
+		return fmt.Errorf("[MCTS] PuctStats: expected %d bytes, got %d", nodeStatsBinarySize+8, len(data))
+	}
+
+	if err := p.NodeStats.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&p.prior, binary.LittleEndian.Uint64(data[nodeStatsBinarySize:]))
+	return nil
+}
+
+// Move priors provider, called right after ExpandNode (with the position set to the expanded node)
+type PriorGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	GameOperations[T, S, R, O]
+	// Fill 'priors' with the (unnormalized) weights of the parent's children,
+	// priors[i] belongs to parent.Children[i]
+	Priors(parent *NodeBase[T, S], priors []float64)
+}
+
+// Stats that can hold a prior, if S doesn't implement it,
+// PriorGameOperations are simply ignored
+type priorStats interface {
+	Prior() float64
+	SetPrior(float64)
+}
+
+// Normalizes the priors, so that they sum up to 1, if that's not possible
+// (no positive weights), priors are set to uniform distribution
+func normalizePriors(priors []float64) {
+	sum := 0.0
+	for i := range priors {
+		if priors[i] < 0 || math.IsNaN(priors[i]) {
+			priors[i] = 0
+		}
+		sum += priors[i]
+	}
+
+	if sum <= 0 || math.IsInf(sum, 0) {
+		for i := range priors {
+			priors[i] = 1 / float64(len(priors))
+		}
+		return
+	}
+
+	for i := range priors {
+		priors[i] /= sum
+	}
+}
+
+// Sets the priors of freshly expanded 'node' children, using the PriorEvaluator or PriorGameOperations.
+// Without them, priors given by ExpandNode are normalized (uniform if none were given).
+// The last 'widened' children were just added by the progressive widening, every prior is
+// normalized again, and the added ones without a prior get an equal share.
+func (mcts *MCTS[T, S, R, O, A]) setPriors(node *NodeBase[T, S], widened int, ops O) {
+	if len(node.Children) == 0 {
+		return
+	}
+
+	if _, ok := any(node.Children[0].Stats).(priorStats); !ok {
+		return
+	}
+
+	priors := make([]float64, len(node.Children))
+	if priorEval, ok := mcts.evaluator.(PriorEvaluator[T, S, R, O]); ok {
+		priorEval.Priors(ops, node, priors)
+	} else if priorOps, ok := GameOperations[T, S, R, O](ops).(PriorGameOperations[T, S, R, O]); ok {
+		priorOps.Priors(node, priors)
+	} else {
+		for i := range node.Children {
+			priors[i] = any(node.Children[i].Stats).(priorStats).Prior()
+			if i >= len(node.Children)-widened && !(priors[i] > 0) {
+				priors[i] = 1 / float64(len(node.Children))
+			}
+		}
+	}
+	normalizePriors(priors)
+
+	for i := range node.Children {
+		any(node.Children[i].Stats).(priorStats).SetPrior(priors[i])
+	}
+}
+
+// How the value of unvisited children (First Play Urgency) is computed
+type FpuMode int
+
+const (
+	// Parent's value reduced by FpuValue
+	FpuRelative FpuMode = iota
+	// Constant FpuValue
+	FpuAbsolute
+)
+
+type PUCT[T MoveLike, S PuctStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	DefaultBackprop[T, S, R, O]
+	ExplorationParam float64 // c_puct
+	FpuMode          FpuMode
+	FpuValue         float64
+}
+
+func NewPUCT[T MoveLike, S PuctStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](explorationParam float64) *PUCT[T, S, R, O] {
+	return &PUCT[T, S, R, O]{
+		ExplorationParam: explorationParam,
+		FpuMode:          FpuRelative,
+		FpuValue:         0.2,
+	}
+}
+
+func (p *PUCT[T, S, R, O]) SetExplorationParam(c float64) *PUCT[T, S, R, O] {
+	p.ExplorationParam = max(0, c)
+	return p
+}
+
+// Set the first play urgency, for FpuRelative 'value' is the reduction
+// of the parent's value, for FpuAbsolute it's the value itself (in [0, 1])
+func (p *PUCT[T, S, R, O]) SetFpu(mode FpuMode, value float64) *PUCT[T, S, R, O] {
+	p.FpuMode = mode
+	p.FpuValue = value
+	return p
+}
+
+// Value of the unvisited child of 'parent'
+func (p *PUCT[T, S, R, O]) fpu(parent *NodeBase[T, S]) float64 {
+	if p.FpuMode == FpuAbsolute {
+		return p.FpuValue
+	}
+
+	// Parent's Q is from the enemy's perspective
+	parentValue := 0.5
+	if parent.Stats.RealVisits() > 0 {
+		parentValue = 1 - float64(parent.Stats.AvgQ())
+	}
+	return max(0, parentValue-p.FpuValue)
+}
+
+func (p *PUCT[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := math.Inf(-1)
+	index := 0
+	sqrtParentVisits := math.Sqrt(float64(max(1, parent.Stats.N())))
+	fpu := p.fpu(parent)
+	var child *NodeBase[T, S]
+	var visits, vl int32
+	var q float64
+
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
+		child = &children[i]
+		if child.ProvenLoss() {
+			// No point in choosing a losing move (see MCTS.SetSolver)
+			continue
+		}
+		visits, vl = child.Stats.GetVvl()
+
+		if visits-vl == 0 {
+			q = fpu
+		} else {
+			q = float64(child.Stats.Q()) / float64(visits)
+		}
+
+		// PUCT: Q/N + c * P * sqrt(parent_visits) / (1 + N)
+		puct := q + p.ExplorationParam*child.Stats.Prior()*sqrtParentVisits/float64(1+visits)
+
+		if puct > best {
+			best = puct
+			index = i
+		}
+	}
+
+	return &children[index]
+}
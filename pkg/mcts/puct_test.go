@@ -0,0 +1,107 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Same game as DummyOps, but with PuctStats and priors favouring 'priorMove'
+const priorMove = Move(3)
+
+type PriorOps struct {
+	depth int
+	rand  *rand.Rand
+}
+
+func (d *PriorOps) Reset()          {}
+func (d *PriorOps) Traverse(m Move) { d.depth++ }
+func (d *PriorOps) BackTraverse()   { d.depth-- }
+
+func (d *PriorOps) ExpandNode(parent *NodeBase[Move, *PuctStats]) uint32 {
+	if d.depth >= 8 {
+		return 0
+	}
+
+	parent.Children = make([]NodeBase[Move, *PuctStats], branchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), false, &PuctStats{})
+	}
+	return branchFactor
+}
+
+func (d *PriorOps) Priors(parent *NodeBase[Move, *PuctStats], priors []float64) {
+	for i := range priors {
+		priors[i] = 1
+	}
+	priors[priorMove] = 50
+}
+
+func (d *PriorOps) Rollout() Result {
+	return Result(d.rand.Intn(3)) / 2
+}
+
+func (d *PriorOps) SetRand(r *rand.Rand) {
+	d.rand = r
+}
+
+func (d *PriorOps) Clone() *PriorOps {
+	return &PriorOps{depth: d.depth}
+}
+
+func TestPuctPriors(t *testing.T) {
+	tree := NewMTCS(
+		NewPUCT[Move, *PuctStats, Result, *PriorOps](1.5),
+		&PriorOps{},
+		MultithreadTreeParallel,
+		&PuctStats{},
+	)
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	sum := 0.0
+	for i := range tree.Root.Children {
+		sum += tree.Root.Children[i].Stats.Prior()
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Fatalf("Priors are not normalized, sum = %f", sum)
+	}
+
+	// Rollouts are random, so the prior should decide
+	if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != priorMove {
+		t.Fatalf("Expected move %d to be the most visited, got %d", priorMove, best.Move)
+	}
+}
+
+func TestPuctUniformPriors(t *testing.T) {
+	priors := []float64{0, -1, math.NaN(), 0}
+	normalizePriors(priors)
+	for _, p := range priors {
+		if p != 0.25 {
+			t.Fatalf("Expected uniform priors, got %v", priors)
+		}
+	}
+
+	priors = []float64{1, 3}
+	normalizePriors(priors)
+	if priors[0] != 0.25 || priors[1] != 0.75 {
+		t.Fatalf("Wrong normalization: %v", priors)
+	}
+}
+
+func TestPuctFpu(t *testing.T) {
+	parent := newRootNode[Move](false, &PuctStats{})
+	parent.Stats.SetVvl(10, 0)
+	parent.Stats.AddQ(2) // enemy's perspective, 0.8 for us
+
+	puct := NewPUCT[Move, *PuctStats, Result, *PriorOps](1.0)
+	if fpu := puct.fpu(parent); math.Abs(fpu-0.6) > 1e-9 {
+		t.Fatalf("Expected relative fpu 0.6, got %f", fpu)
+	}
+
+	puct.SetFpu(FpuAbsolute, 1.0)
+	if fpu := puct.fpu(parent); fpu != 1.0 {
+		t.Fatalf("Expected absolute fpu 1.0, got %f", fpu)
+	}
+}
@@ -1,6 +1,8 @@
 package mcts
 
 import (
+	"encoding/binary"
+	"fmt"
 	"math"
 	"slices"
 	"sync/atomic"
@@ -73,6 +75,27 @@ func (r *RaveStats) AddNRAVE(playouts int32) {
 	atomic.AddInt32(&r.n_rave, playouts)
 }
 
+// Encodes the node counters and AMAF statistics, implements encoding.BinaryMarshaler
+func (r *RaveStats) MarshalBinary() ([]byte, error) {
+	buf := r.NodeStats.appendBinary(make([]byte, 0, nodeStatsBinarySize+8))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(r.RawQRAVE()))
+	return binary.LittleEndian.AppendUint32(buf, uint32(r.NRAVE())), nil
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (r *RaveStats) UnmarshalBinary(data []byte) error {
+	if len(data) < nodeStatsBinarySize+8 {
+		return fmt.Errorf("[MCTS] RaveStats: expected %d bytes, got %d", nodeStatsBinarySize+8, len(data))
+	}
+
+	if err := r.NodeStats.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	atomic.StoreInt32(&r.q_rave, int32(binary.LittleEndian.Uint32(data[nodeStatsBinarySize:])))
+	atomic.StoreInt32(&r.n_rave, int32(binary.LittleEndian.Uint32(data[nodeStatsBinarySize+4:])))
+	return nil
+}
+
 // Source: https://en.wikipedia.org/wiki/Monte_Carlo_tree_search#Improvements
 // function should be close to one and to zero for relatively small and relatively big 'n' and 'n_rave' respectively.
 type RaveBetaFnType func(n, n_rave int32) float64
@@ -127,23 +150,39 @@ func (r *RAVE[T, S, R, O]) SetBetaFunction(f RaveBetaFnType) *RAVE[T, S, R, O] {
 }
 
 func (r RAVE[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	return r.selectAMAF(parent, parent)
+}
+
+// Selects the parent's child, using the AMAF statistics of the same moves among the children
+// of the 'ref' node (the parent itself for RAVE, see GRAVE)
+func (r RAVE[T, S, R, O]) selectAMAF(parent, ref *NodeBase[T, S]) *NodeBase[T, S] {
 	// Is that's a terminal node, simply return itself, there is no children anyway
 	// and on the rollout we will exit early, since the position is terminated
 	if parent.Terminal() {
 		return parent
 	}
 
-	var child *NodeBase[T, S]
+	var child, amaf *NodeBase[T, S]
 	var actualVisits, visits, vl int32
+	var refChildren []NodeBase[T, S]
+	hint := 0
+	if ref != parent {
+		refChildren = ref.VisibleChildren()
+	}
 
 	max := float64(-1)
 	index := 0
 	lnParentVisits := math.Log(float64(parent.Stats.N()))
 
-	for i := 0; i < len(parent.Children); i++ {
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
 
 		// Get the variables
-		child = &parent.Children[i]
+		child = &children[i]
+		if child.ProvenLoss() {
+			// No point in choosing a losing move (see MCTS.SetSolver)
+			continue
+		}
 		visits, vl = child.Stats.GetVvl()
 		actualVisits = visits - vl
 
@@ -152,13 +191,20 @@ func (r RAVE[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
 			return child
 		}
 
+		amaf = child
+		if ref != parent {
+			amaf, hint = findMove(refChildren, child.Move, hint)
+		}
+
 		q := float64(child.Stats.Q()) / float64(visits)
 		b := 0.0
 		amafq := 0.0
-		if nRave := child.Stats.NRAVE(); nRave > 0 {
+		if amaf == nil {
+			// Move wasn't legal in the reference node, no AMAF statistics
+		} else if nRave := amaf.Stats.NRAVE(); nRave > 0 {
 			// specified in vars.go
 			b = r.BetaFunction(actualVisits, nRave)
-			amafq = float64(child.Stats.QRAVE()) / float64(nRave)
+			amafq = float64(amaf.Stats.QRAVE()) / float64(nRave)
 		}
 
 		ucb := (1.0-b)*q + b*amafq +
@@ -170,7 +216,7 @@ func (r RAVE[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
 		}
 	}
 
-	return &parent.Children[index]
+	return &children[index]
 }
 
 func (b RAVE[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result R) {
@@ -183,14 +229,23 @@ func (b RAVE[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result R) {
 		node.Stats.AddQ(v)
 
 		// Reverse virtual loss for non-root
-		if node.Parent != nil {
+		if node.Parent != nil && node.Parent.Chance() {
+			// Outcomes aren't player's moves, and the chance node
+			// shares their perspective (see ChanceGameOperations)
+			node.Stats.AddVvl(1-VirtualLoss, -VirtualLoss)
+			v = 1.0 - v
+			node = node.Parent
+			ops.BackTraverse()
+			continue
+		} else if node.Parent != nil {
 			node.Stats.AddVvl(1-VirtualLoss, -VirtualLoss)
 
 			mvs := result.Moves()
 			var ch *NodeBase[T, S]
-			for i := range node.Parent.Children {
+			siblings := node.Parent.VisibleChildren()
+			for i := range siblings {
 				// Check if the child contains a move from the playout
-				ch = &node.Parent.Children[i]
+				ch = &siblings[i]
 				if slices.Contains(mvs, ch.Move) {
 					ch.Stats.AddQRAVE(v)
 					ch.Stats.AddNRAVE(1)
@@ -2,27 +2,18 @@ package mcts
 
 import (
 	"math/rand"
-	"runtime"
 )
 
-// Use when started multi-threaded search and want it to synchronize with this thread
+// Use when started multi-threaded search and want it to synchronize with this thread,
+// returns when the search is done (and the results were merged), safe to call from any goroutine
 func (mcts *MCTS[T, S, R, O, A]) Synchronize() {
-	if mcts.shouldMerge() {
-		// Wait for the merge to finish
-		for !mcts.merged.Load() {
-			runtime.Gosched()
-		}
-	} else {
-		// Just wait for all threads to finish
-		mcts.wg.Wait()
-	}
+	mcts.wait()
 }
 
 func (mcts *MCTS[T, S, R, O, A]) mergeResults() {
 	for _, other := range mcts.roots[1:] {
 		mergeResult(mcts.Root, other)
 	}
-	mcts.merged.Store(true)
 	mcts.roots = nil
 }
 
@@ -73,27 +64,52 @@ func mergeResult[T MoveLike, S NodeStatsLike[S]](root *NodeBase[T, S], other *No
 // Used for pre-mature termination of search
 func (mcts *MCTS[T, S, R, O, A]) prematureCleanup() {
 	mcts.Limiter.Stop()
-	mcts.Limiter.EvaluateStopReason(mcts.Size(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles()))
+	mcts.Limiter.EvaluateStopReason(mcts.limiterSize(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles()))
 	mcts.invokeListener(mcts.listener.onStop, false)
 }
 
-// Run multi-treaded search, to wait for the result, call Synchronize
+// Run multi-treaded search, to wait for the result, call Synchronize.
+// If the previous search is still running, stops it and waits for it first (ends pondering as well).
 func (mcts *MCTS[T, S, R, O, A]) SearchMultiThreaded() {
+	defer mcts.acquireTree()()
+	mcts.endPondering()
+	mcts.startSearch()
+}
+
+// Starts the search threads, the lifecycle mutex must be held, and the previous search finished
+func (mcts *MCTS[T, S, R, O, A]) startSearch() {
 	if mcts.Root.Terminal() {
 		// OnStop must always be called, when search terminates
+		run := mcts.beginRun()
 		mcts.prematureCleanup()
+		mcts.finishRun(run)
 		return
 	}
 
 	mcts.setupSearch()
+	run := mcts.beginRun()
 	threads := max(1, mcts.Limiter.Limits().NThreads)
 
+	// Nothing left to search, but keep the search lifecycle (listeners, synchronization)
+	if mcts.solver && mcts.Root.Proven() {
+		mcts.Limiter.SetSolved(true)
+	}
+
 	if !mcts.Root.Expanded() && mcts.tryExpandingWarn(mcts.Root) {
 		// Root is terminal, but wasn't marked as such
 		mcts.prematureCleanup()
+		mcts.finishRun(run)
 		panic("[MCTS] SearchMultiThreaded: root node is not terminal, but ExpandNode returned no children, search aborted")
 	}
 
+	// Fresh noise for every search, cloned with the root for the root-parallel threads
+	mcts.applyRootNoise(mcts.Root)
+
+	// Leaf-parallel helpers are started by the main thread, for every leaf
+	if mcts.useLeafParallel() {
+		threads = 1
+	}
+
 	// Create a slice of root nodes
 	mcts.roots = make([]*NodeBase[T, S], threads)
 	for id := range mcts.roots {
@@ -106,10 +122,9 @@ func (mcts *MCTS[T, S, R, O, A]) SearchMultiThreaded() {
 		}
 	}
 
-	// Fix: Not incrementing by 1 in the loop, because the main searching thread,
-	// will wait after finishing it's search, and if the search was *very* brief,
-	// increment on the waitgroup will cause that panic.
-	mcts.wg.Add(threads)
+	// Main thread waits for the others before the search is done, so that Synchronize
+	// returns only after every thread finished (and the results were merged)
+	mcts.helpers.Add(threads - 1)
 	for id := range mcts.roots {
 		// Start the search in a separate goroutine
 		go mcts.Search(mcts.roots[id], mcts.ops.Clone(), id)
@@ -127,7 +142,6 @@ func (mcts *MCTS[T, S, R, O, A]) setupSearch() {
 	mcts.cps.Store(0)
 	mcts.cycles.Store(0)
 	mcts.maxdepth.Store(0)
-	mcts.merged.Store(false)
 }
 
 // Actual search function implementation, simply calls:
@@ -135,6 +149,7 @@ func (mcts *MCTS[T, S, R, O, A]) setupSearch() {
 // 1. selection - to choose the most promising node
 //
 // 2. rollout - to simulate the user-defined game, and get the result of a playout
+// (or evaluate the leaf with the Evaluator, if set)
 //
 // 3. backpropagate - to increment counters up to the root
 //
@@ -149,28 +164,77 @@ func (mcts *MCTS[T, S, R, O, A]) Search(root *NodeBase[T, S], ops O, threadId in
 	}
 
 	var node *NodeBase[T, S]
+	run := mcts.lifecycle.run.Load()
+	isOps, _ := GameOperations[T, S, R, O](ops).(ISGameOperations[T, S, R, O])
+
+	prune := mcts.Limiter.Limits().Prune
+
+	var workers *leafWorkers[T, S, R, O]
+	if threadId == mainThreadId && mcts.useLeafParallel() {
+		workers = newLeafWorkers[T, S, R, O](mcts.Limiter.Limits().NThreads)
+	}
+
+	iterations := 0
+	for mcts.Limiter.Ok(mcts.limiterSize(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles())) {
+
+		// Pruning must not run in the middle of the iteration
+		if prune {
+			mcts.pruneMx.RLock()
+		}
 
-	for mcts.Limiter.Ok(mcts.Size(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles())) {
+		// Sample the hidden information for this iteration
+		if isOps != nil {
+			isOps.Determinize()
+		}
 
 		// Choose the most promising node
 		node = mcts.Selection(root, ops, threadRand, threadId)
-		// Get the result of the rollout/playout
-		mcts.strategy.Backpropagate(ops, node, ops.Rollout())
+		cycles := uint32(1)
+
+		if workers != nil {
+			// Simulate the leaf on every thread at once
+			cycles = mcts.searchLeaf(ops, node, workers)
+		} else {
+			// Get the result of the rollout/playout (or the evaluator)
+			result := mcts.leafResult(ops, node)
+			mcts.strategy.Backpropagate(ops, node, result)
+
+			// Prove the terminal nodes, and propagate the proofs
+			if mcts.solver {
+				mcts.solve(node, result)
+			}
+		}
 
 		// Increment cycle count and store the cps
-		mcts.cycles.Add(1)
+		mcts.cycles.Add(cycles)
 		mcts.cps.Store(uint32(mcts.Cycles()) * 1000 / mcts.Limiter.Elapsed())
 
+		// Let the limiter know how the root children compare
+		if threadId == mainThreadId {
+			iterations++
+			if iterations%rootUpdateInterval == 0 {
+				mcts.updateRoot(root)
+			}
+		}
+
 		// Invoke the 'onCycle' listener
 		if threadId == mainThreadId && mcts.listener.onCycle != nil &&
-			mcts.Root.Stats.N()%int32(mcts.listener.nCycles) == 0 {
+			crossedMultiple(mcts.Root.Stats.N(), int32(cycles), int32(mcts.listener.nCycles)) {
 			mcts.invokeListener(mcts.listener.onCycle, true)
 		}
+
+		if prune {
+			mcts.pruneMx.RUnlock()
+			if mcts.shouldPrune() {
+				mcts.prune()
+			}
+		}
 	}
 
 	// Evaluate the stop reason, only main thread will do this
 	if threadId == mainThreadId {
-		mcts.Limiter.EvaluateStopReason(mcts.Size(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles()))
+		mcts.transition(SearchRunning, SearchStopping)
+		mcts.Limiter.EvaluateStopReason(mcts.limiterSize(), uint32(mcts.MaxDepth()), uint32(mcts.Cycles()))
 	}
 
 	// Stop every search thread
@@ -180,17 +244,55 @@ func (mcts *MCTS[T, S, R, O, A]) Search(root *NodeBase[T, S], ops O, threadId in
 	if threadId == mainThreadId {
 		// onStop is the only listener that is always called, even if the search was stopped
 		mcts.invokeListener(mcts.listener.onStop, false)
-		mcts.wg.Done()
 
 		// Wait for other threads to finish
-		mcts.wg.Wait()
+		mcts.helpers.Wait()
+
+		// Children added by the progressive widening were visible only to the search
+		if mcts.widening != nil {
+			commitChildren(root)
+			for _, other := range mcts.roots {
+				commitChildren(other)
+			}
+		}
+
 		// If we are in 'root parallel' mode, merge the results
-		if mcts.shouldMerge() {
+		if mcts.shouldMerge() && mcts.transition(SearchStopping, SearchMerging) {
 			mcts.mergeResults()
 		}
+		mcts.removeRootNoise(root)
+		mcts.finishRun(run)
 	} else {
-		mcts.wg.Done()
+		mcts.helpers.Done()
+	}
+}
+
+// Reports the 2 most visited root children to the limiter
+func (mcts *MCTS[T, S, R, O, A]) updateRoot(root *NodeBase[T, S]) {
+	if !root.Expanded() {
+		return
 	}
+
+	// Cycles first, the visits counted after them can only be higher
+	info := RootInfo{Best: -1, Cycles: uint32(mcts.Cycles())}
+	children := root.VisibleChildren()
+	for i := range children {
+		// Virtual visits of the running iterations may not end up in the chosen child
+		visits := uint32(max(0, children[i].Stats.RealVisits()))
+		if info.Best == -1 || visits > info.BestVisits {
+			info.SecondVisits = info.BestVisits
+			info.Best, info.BestVisits = i, visits
+		} else if visits > info.SecondVisits {
+			info.SecondVisits = visits
+		}
+	}
+	info.Undecided = mcts.rootUndecided(root)
+	mcts.Limiter.UpdateRoot(info)
+}
+
+// Whether 'n' reached the next multiple of 'step', after increasing by 'added'
+func crossedMultiple(n, added, step int32) bool {
+	return n/step != (n-added)/step
 }
 
 // Selects next child to expand, by user-defined selection policy
@@ -198,8 +300,18 @@ func (mcts *MCTS[T, S, R, O, A]) Selection(root *NodeBase[T, S], ops O, threadRa
 
 	node := root
 	depth := int32(0)
-	for node.Expanded() {
-		node = mcts.strategy.Select(node, root)
+	isOps, _ := GameOperations[T, S, R, O](ops).(ISGameOperations[T, S, R, O])
+	for node.Expanded() && !(mcts.solver && node.Proven()) {
+		if mcts.widening != nil {
+			mcts.widen(node, ops)
+		}
+
+		next := mcts.selectChild(node, root, isOps, SelectionContext{Rand: threadRand, ThreadId: threadId, Depth: depth})
+		if next == nil {
+			// Nothing legal in this determinization
+			break
+		}
+		node = next
 		ops.Traverse(node.Move)
 		depth++
 
@@ -208,39 +320,34 @@ func (mcts *MCTS[T, S, R, O, A]) Selection(root *NodeBase[T, S], ops O, threadRa
 	}
 
 	// Add new children to this node, after finding leaf node
-	if node.Stats.RealVisits() > 0 && !node.Terminal() {
+	if node.Stats.RealVisits() > 0 && !node.Terminal() && !(mcts.solver && node.Proven()) {
 		// Expand the node, only if needed (expand flag is 0)
 		if mcts.Limiter.Expand() && node.CanExpand() {
-			v := ops.ExpandNode(node)
+			v := mcts.expandNode(node, ops)
 			if len(node.Children) == 0 {
 				// Allocation failed, this may happen even if ops.ExpandNode
 				// is properly implemented, undo the expanding state
 				node.CancelExpanding()
 			} else {
-				// Now update it's state
+				// Now update it's state, publishing the children to other threads
 				node.FinishExpanding()
 				mcts.size.Add(v)
 			}
-		}
-
-		// Currently expanding
-		first := true
-		for node.Expanding() {
-			if first {
-				// If this is the first time, increment the collision counter
-				mcts.collisionCount.Add(1)
-				first = false
-			}
-			runtime.Gosched()
+		} else if node.Expanding() && !node.Expanded() {
+			// Other thread is expanding this node, instead of waiting for it,
+			// roll out from the leaf (children can't be read until it's expanded)
+			mcts.collisionCount.Add(1)
 		}
 
 		// Already set
 		if node.Expanded() {
-			node = &node.Children[threadRand.Int31()%int32(len(node.Children))]
-			ops.Traverse(node.Move)
-			depth++
-			// Apply again virtual loss
-			node.Stats.AddVvl(VirtualLoss, VirtualLoss)
+			if next := randomChild(node, isOps, threadRand); next != nil {
+				node = next
+				ops.Traverse(node.Move)
+				depth++
+				// Apply again virtual loss
+				node.Stats.AddVvl(VirtualLoss, VirtualLoss)
+			}
 		}
 	}
 
@@ -0,0 +1,191 @@
+package mcts
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// DummyOps with a slow ExpandNode, recording every expanded node
+type SlowExpandOps struct {
+	DummyOps
+	delay    time.Duration
+	mx       *sync.Mutex
+	expanded map[*NodeBase[Move, *NodeStats]]int
+}
+
+func NewSlowExpandOps(delay time.Duration) *SlowExpandOps {
+	return &SlowExpandOps{
+		delay:    delay,
+		mx:       &sync.Mutex{},
+		expanded: make(map[*NodeBase[Move, *NodeStats]]int),
+	}
+}
+
+func (o *SlowExpandOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if o.depth >= 8 {
+		return 0
+	}
+
+	o.mx.Lock()
+	o.expanded[parent]++
+	o.mx.Unlock()
+
+	// Children are visible in the node long before they are initialized
+	parent.Children = make([]NodeBase[Move, *NodeStats], branchFactor)
+	time.Sleep(o.delay)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), false, &NodeStats{})
+	}
+	return branchFactor
+}
+
+func (o *SlowExpandOps) Clone() *SlowExpandOps {
+	return &SlowExpandOps{DummyOps: *o.DummyOps.Clone(), delay: o.delay, mx: o.mx, expanded: o.expanded}
+}
+
+func newSlowExpandMCTS(delay time.Duration) (*MCTS[Move, *NodeStats, Result, *SlowExpandOps, *UCB1[Move, *NodeStats, Result, *SlowExpandOps]], *SlowExpandOps) {
+	ops := NewSlowExpandOps(delay)
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *SlowExpandOps](0.45),
+		ops,
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	return tree, ops
+}
+
+func TestSelectionDoesNotWaitForExpansion(t *testing.T) {
+	tree, ops := newSlowExpandMCTS(0)
+
+	// Root with a single, visited child
+	tree.Root.Children = []NodeBase[Move, *NodeStats]{*NewBaseNode(tree.Root, 0, false, &NodeStats{})}
+	tree.Root.FinishExpanding()
+	child := &tree.Root.Children[0]
+	child.Stats.AddVvl(1, 0)
+
+	// Other thread is expanding the child, and never finishes
+	if !child.CanExpand() {
+		t.Fatal("Expected to acquire the expansion of the child")
+	}
+
+	done := make(chan *NodeBase[Move, *NodeStats])
+	go func() {
+		done <- tree.Selection(tree.Root, ops.Clone(), rand.New(rand.NewSource(1)), 1)
+	}()
+
+	select {
+	case leaf := <-done:
+		if leaf != child {
+			t.Fatal("Expected the selection to stop at the node being expanded")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Selection is waiting for the other thread's expansion")
+	}
+
+	if tree.CollisionCount() != 1 {
+		t.Fatalf("Expected 1 collision, got %d", tree.CollisionCount())
+	}
+	if child.Expanded() || ops.expanded[child] != 0 {
+		t.Fatal("Node being expanded by other thread must not be expanded again")
+	}
+}
+
+func TestSlowExpansionManyThreads(t *testing.T) {
+	const cycles, threads = 3000, 16
+	tree, ops := newSlowExpandMCTS(200 * time.Microsecond)
+	tree.SetLimits(DefaultLimits().SetCycles(cycles).SetThreads(threads))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	for node, n := range ops.expanded {
+		if n != 1 {
+			t.Fatalf("Node %v was expanded %d times", node.Move, n)
+		}
+	}
+
+	if n := tree.Root.Stats.N(); n < cycles || n >= cycles+threads {
+		t.Fatalf("Expected %d root visits, got %d", cycles, n)
+	}
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+	checkVirtualLoss(t, tree.Root)
+	t.Logf("collisions %d (%.2f%%)", tree.CollisionCount(), tree.CollisionFactor()*100)
+}
+
+func TestListenerReadsDuringExpansion(t *testing.T) {
+	const threads = 16
+	tree, _ := newSlowExpandMCTS(100 * time.Microsecond)
+
+	// Main thread walks the tree on every cycle, while the others are expanding it
+	pvs := 0
+	listener := NewStatsListener[Move]()
+	listener.OnCycle(func(stats ListenerTreeStats[Move]) {
+		pvs += len(stats.Lines)
+	}).SetCycleInterval(1)
+	tree.SetListener(listener)
+
+	tree.SetLimits(DefaultLimits().SetCycles(3000).SetThreads(threads).SetMultiPv(3))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	t.Logf("listener saw %d lines", pvs)
+	if tree.Size() != uint32(countTreeNodes(tree.Root)) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), countTreeNodes(tree.Root))
+	}
+}
+
+// Walks the published part of the tree, the way a reader outside the search may
+func walkExpanded(node *NodeBase[Move, *NodeStats]) (nodes int, visits int32) {
+	nodes, visits = 1, node.Stats.N()
+	if !node.Expanded() {
+		return
+	}
+
+	children := node.VisibleChildren()
+	for i := range children {
+		n, v := walkExpanded(&children[i])
+		nodes, visits = nodes+n, visits+v
+	}
+	return
+}
+
+func TestConcurrentReadersDuringSearch(t *testing.T) {
+	const threads, readers = 16, 4
+	tree, _ := newSlowExpandMCTS(100 * time.Microsecond)
+	tree.SetLimits(DefaultLimits().SetCycles(3000).SetThreads(threads).SetMultiPv(3))
+
+	// Readers walk the tree from their own goroutines, while the search threads are expanding it
+	done := make(chan struct{})
+	wg := sync.WaitGroup{}
+	walks := make([]int, readers)
+	for r := range readers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				walkExpanded(tree.Root)
+				tree.MultiPv(BestChildMostVisits)
+				walks[r]++
+			}
+		}()
+	}
+
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+	close(done)
+	wg.Wait()
+
+	t.Logf("walks per reader: %v", walks)
+	if nodes, _ := walkExpanded(tree.Root); nodes != int(tree.Size()) {
+		t.Fatalf("Size %d doesn't match the tree (%d nodes)", tree.Size(), nodes)
+	}
+}
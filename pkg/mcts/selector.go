@@ -0,0 +1,225 @@
+package mcts
+
+import "math"
+
+// Best child selection, after (or during) the search
+// Reference: https://dke.maastrichtuniversity.nl/m.winands/documents/pMCTS.pdf (Chaslot et al., Progressive Strategies for MCTS)
+//
+// BestChild resolves the chance nodes and the proven values first (see MCTS.SetSolver), and then asks the
+// selector for the policy. BestChildDefault uses the selector set with SetBestChildSelector (the most visited
+// child if none was set), which is also used by BestMove, RootScore and the listener's lines.
+
+// Chooses the best child, the values are from the perspective of the player choosing the child
+type BestChildSelector[T MoveLike, S NodeStatsLike[S]] interface {
+	// The best of the 'candidates' (children of 'node', without the proven losses),
+	// or nil if none of them can be chosen
+	Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S]
+}
+
+// Selector which may need more search to make its choice (see RobustMaxSelector).
+// While the root is undecided, the search continues beyond its cycles and movetime limits
+// (by at most 50%), and beyond the soft deadline of the clock.
+type UndecidedSelector[T MoveLike, S NodeStatsLike[S]] interface {
+	BestChildSelector[T, S]
+	// Whether the choice among the 'candidates' isn't clear yet
+	Undecided(node *NodeBase[T, S], candidates []*NodeBase[T, S]) bool
+}
+
+// Max child: the most visited one, the go-to choice for MCTS
+type MostVisitsSelector[T MoveLike, S NodeStatsLike[S]] struct{}
+
+func (MostVisitsSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	var best *NodeBase[T, S]
+	maxVisits := int32(0)
+	for _, child := range candidates {
+		if visits := child.Stats.RealVisits(); visits > maxVisits {
+			best, maxVisits = child, visits
+		}
+	}
+	return best
+}
+
+// Best average value, among the children with more than MinVisits visits,
+// and at least MinVisitsRatio of the most visited child's visits
+type WinRateSelector[T MoveLike, S NodeStatsLike[S]] struct {
+	MinVisits      int32
+	MinVisitsRatio float64
+}
+
+func NewWinRateSelector[T MoveLike, S NodeStatsLike[S]](minVisits int32, minVisitsRatio float64) *WinRateSelector[T, S] {
+	return &WinRateSelector[T, S]{MinVisits: minVisits, MinVisitsRatio: minVisitsRatio}
+}
+
+func (w *WinRateSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	maxVisits := int32(0)
+	for _, child := range candidates {
+		maxVisits = max(maxVisits, child.Stats.N())
+	}
+
+	var best *NodeBase[T, S]
+	bestValue := math.Inf(-1)
+	for _, child := range candidates {
+		visits := child.Stats.RealVisits()
+		if visits <= w.MinVisits || float64(visits) < w.MinVisitsRatio*float64(maxVisits) {
+			continue
+		}
+
+		// Expected value for the chance nodes
+		if value := nodeValue(child); value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// Best result seen (single-player games, see SinglePlayerStatsLike), the most visited child otherwise
+type MaxScoreSelector[T MoveLike, S NodeStatsLike[S]] struct{}
+
+func (MaxScoreSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	if len(candidates) == 0 {
+		return nil
+	}
+	if _, ok := any(candidates[0].Stats).(bestScoreStats); !ok {
+		return MostVisitsSelector[T, S]{}.Select(node, candidates)
+	}
+
+	var best *NodeBase[T, S]
+	bestScore := Result(-1)
+	for _, child := range candidates {
+		if child.Stats.RealVisits() == 0 {
+			continue
+		}
+		if score := any(child.Stats).(bestScoreStats).Best(); score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// Robust-max child: the most visited child, which has the best value as well. The value is compared
+// among the children with at least MinVisitsRatio of the most visited child's visits. If they disagree,
+// the root is undecided, and the search continues (see UndecidedSelector), falling back to the most visited child.
+type RobustMaxSelector[T MoveLike, S NodeStatsLike[S]] struct {
+	MinVisitsRatio float64
+}
+
+func NewRobustMaxSelector[T MoveLike, S NodeStatsLike[S]]() *RobustMaxSelector[T, S] {
+	return &RobustMaxSelector[T, S]{MinVisitsRatio: 0.1}
+}
+
+func (r *RobustMaxSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	return MostVisitsSelector[T, S]{}.Select(node, candidates)
+}
+
+func (r *RobustMaxSelector[T, S]) Undecided(node *NodeBase[T, S], candidates []*NodeBase[T, S]) bool {
+	mostVisited := MostVisitsSelector[T, S]{}.Select(node, candidates)
+	if mostVisited == nil {
+		return false
+	}
+
+	best := (&WinRateSelector[T, S]{MinVisitsRatio: r.MinVisitsRatio}).Select(node, candidates)
+	return best != nil && best != mostVisited && nodeValue(best) > nodeValue(mostVisited)
+}
+
+// Lower confidence bound: the child with the best Wilson lower bound of its value,
+// Z is the number of standard deviations (1.96 for 95% confidence)
+type LCBSelector[T MoveLike, S NodeStatsLike[S]] struct {
+	Z float64
+}
+
+func NewLCBSelector[T MoveLike, S NodeStatsLike[S]](z float64) *LCBSelector[T, S] {
+	return &LCBSelector[T, S]{Z: z}
+}
+
+func (l *LCBSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	var best *NodeBase[T, S]
+	bestBound := math.Inf(-1)
+	for _, child := range candidates {
+		visits := child.Stats.RealVisits()
+		if visits == 0 {
+			continue
+		}
+		if bound := wilsonLowerBound(nodeValue(child), float64(visits), l.Z); bound > bestBound {
+			best, bestBound = child, bound
+		}
+	}
+	return best
+}
+
+// Wilson score interval lower bound, of the value 'p' after 'n' samples
+func wilsonLowerBound(p, n, z float64) float64 {
+	p = min(1, max(0, p))
+	z2 := z * z
+	center := p + z2/(2*n)
+	margin := z * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return (center - margin) / (1 + z2/n)
+}
+
+// Secure child: the child maximizing value - A / sqrt(visits)
+type SecureChildSelector[T MoveLike, S NodeStatsLike[S]] struct {
+	A float64
+}
+
+func NewSecureChildSelector[T MoveLike, S NodeStatsLike[S]](a float64) *SecureChildSelector[T, S] {
+	return &SecureChildSelector[T, S]{A: a}
+}
+
+func (s *SecureChildSelector[T, S]) Select(node *NodeBase[T, S], candidates []*NodeBase[T, S]) *NodeBase[T, S] {
+	var best *NodeBase[T, S]
+	bestValue := math.Inf(-1)
+	for _, child := range candidates {
+		visits := child.Stats.RealVisits()
+		if visits == 0 {
+			continue
+		}
+		if value := nodeValue(child) - s.A/math.Sqrt(float64(visits)); value > bestValue {
+			best, bestValue = child, value
+		}
+	}
+	return best
+}
+
+// Sets the selector used by BestChildDefault (and BestMove, RootScore, the listener), nil means the most
+// visited child. Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetBestChildSelector(selector BestChildSelector[T, S]) {
+	mcts.selector = selector
+}
+
+// Selector used by BestChildDefault
+func (mcts *MCTS[T, S, R, O, A]) BestChildSelector() BestChildSelector[T, S] {
+	return mcts.selectorFor(BestChildDefault)
+}
+
+func (mcts *MCTS[T, S, R, O, A]) selectorFor(policy BestChildPolicy) BestChildSelector[T, S] {
+	switch policy {
+	case BestChildWinRate:
+		return &WinRateSelector[T, S]{MinVisits: 10}
+	case BestChildMaxScore:
+		return MaxScoreSelector[T, S]{}
+	case BestChildDefault:
+		if mcts.selector != nil {
+			return mcts.selector
+		}
+	}
+	return MostVisitsSelector[T, S]{}
+}
+
+// Children of the node, that may be chosen by the selectors (proven losses are never chosen)
+func selectable[T MoveLike, S NodeStatsLike[S]](children []NodeBase[T, S]) []*NodeBase[T, S] {
+	nodes := make([]*NodeBase[T, S], 0, len(children))
+	for i := range children {
+		if !children[i].ProvenLoss() {
+			nodes = append(nodes, &children[i])
+		}
+	}
+	return nodes
+}
+
+// Whether the configured selector needs more search to choose the root's child
+func (mcts *MCTS[T, S, R, O, A]) rootUndecided(root *NodeBase[T, S]) bool {
+	selector, ok := mcts.selectorFor(BestChildDefault).(UndecidedSelector[T, S])
+	if !ok || root.Chance() || provenBestChild(root, mcts.childrenComplete(root)) != nil {
+		return false
+	}
+	return selector.Undecided(root, selectable(root.VisibleChildren()))
+}
@@ -0,0 +1,147 @@
+package mcts
+
+import (
+	"math"
+	"testing"
+)
+
+// Root with children of the given visits and average values
+func newSelectorTree(visits []int32, values []float64) *DummyMCTS {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	tree.Root.Children = tree.Root.Children[:len(visits)]
+	for i, n := range visits {
+		tree.Root.Children[i].Stats.AddVvl(n, 0)
+		tree.Root.Children[i].Stats.AddQ(Result(float64(n) * values[i]))
+	}
+	return tree
+}
+
+func TestSelectors(t *testing.T) {
+	tree := newSelectorTree([]int32{0, 10, 500, 400}, []float64{0, 0.8, 0.5, 0.6})
+	candidates := selectable(tree.Root.Children)
+
+	selectors := []struct {
+		name     string
+		selector BestChildSelector[Move, *NodeStats]
+		expected Move
+	}{
+		{"MostVisits", MostVisitsSelector[Move, *NodeStats]{}, 2},
+		{"WinRate", NewWinRateSelector[Move, *NodeStats](5, 0), 1},
+		{"WinRateRatio", NewWinRateSelector[Move, *NodeStats](5, 0.1), 3},
+		{"MaxScore", MaxScoreSelector[Move, *NodeStats]{}, 2},
+		{"RobustMax", NewRobustMaxSelector[Move, *NodeStats](), 2},
+		{"LCB", NewLCBSelector[Move, *NodeStats](1.96), 3},
+		{"SecureChild", NewSecureChildSelector[Move, *NodeStats](1), 3},
+		{"SecureChildZero", NewSecureChildSelector[Move, *NodeStats](0), 1},
+	}
+
+	for _, s := range selectors {
+		if best := s.selector.Select(tree.Root, candidates); best == nil || best.Move != s.expected {
+			t.Errorf("%s: expected move %d, got %v", s.name, s.expected, best)
+		}
+		if best := s.selector.Select(tree.Root, nil); best != nil {
+			t.Errorf("%s: expected nil without candidates, got %v", s.name, best.Move)
+		}
+	}
+}
+
+func TestRobustMaxUndecided(t *testing.T) {
+	robust := NewRobustMaxSelector[Move, *NodeStats]()
+
+	// Most visited child doesn't have the best value
+	tree := newSelectorTree([]int32{10, 500, 400}, []float64{0.9, 0.5, 0.6})
+	if !robust.Undecided(tree.Root, selectable(tree.Root.Children)) {
+		t.Fatal("Expected the root to be undecided")
+	}
+
+	// Children below MinVisitsRatio are ignored
+	tree = newSelectorTree([]int32{10, 500, 400}, []float64{0.9, 0.6, 0.5})
+	if robust.Undecided(tree.Root, selectable(tree.Root.Children)) {
+		t.Fatal("Expected the root to be decided")
+	}
+
+	// Only the configured selector can keep the search running
+	if tree.rootUndecided(tree.Root) {
+		t.Fatal("Most visits selector should never be undecided")
+	}
+	tree = newSelectorTree([]int32{10, 500, 400}, []float64{0.9, 0.5, 0.6})
+	tree.SetBestChildSelector(robust)
+	if !tree.rootUndecided(tree.Root) {
+		t.Fatal("Expected the root to be undecided with the robust-max selector")
+	}
+}
+
+func TestLimiterUndecided(t *testing.T) {
+	limiter := NewLimiter(32)
+	limiter.SetLimits(DefaultLimits().SetCycles(1000))
+	limiter.Reset()
+
+	limiter.UpdateRoot(RootInfo{Undecided: true})
+	if !limiter.Ok(1, 1, 1200) {
+		t.Fatal("Expected the undecided search to continue beyond the cycles limit")
+	}
+	if limiter.Ok(1, 1, 1500) {
+		t.Fatal("Expected the undecided search to stop at 150% of the cycles limit")
+	}
+
+	limiter.UpdateRoot(RootInfo{})
+	if limiter.Ok(1, 1, 1200) {
+		t.Fatal("Expected the decided search to stop at the cycles limit")
+	}
+
+	// Stop signal always applies
+	limiter.UpdateRoot(RootInfo{Undecided: true})
+	limiter.SetStop(true)
+	if limiter.Ok(1, 1, 10) {
+		t.Fatal("Expected the stopped search to stop")
+	}
+
+	// Reset forgets the root
+	limiter.Reset()
+	if limiter.Ok(1, 1, 1200) {
+		t.Fatal("Expected the undecided flag to be reset")
+	}
+}
+
+func TestWilsonLowerBound(t *testing.T) {
+	if bound := wilsonLowerBound(0.5, 1e9, 1.96); math.Abs(bound-0.5) > 1e-3 {
+		t.Fatalf("Expected the bound to converge to the value, got %f", bound)
+	}
+	if a, b := wilsonLowerBound(0.8, 10, 1.96), wilsonLowerBound(0.8, 1000, 1.96); a >= b || b >= 0.8 {
+		t.Fatalf("Expected the bound to tighten with the samples, got %f, %f", a, b)
+	}
+	if bound := wilsonLowerBound(0, 10, 1.96); math.Abs(bound) > 1e-12 {
+		t.Fatalf("Expected bound 0 for value 0, got %f", bound)
+	}
+}
+
+func TestConfiguredSelector(t *testing.T) {
+	tree := newSelectorTree([]int32{0, 10, 500, 400}, []float64{0, 0.8, 0.5, 0.6})
+	if _, ok := tree.BestChildSelector().(MostVisitsSelector[Move, *NodeStats]); !ok {
+		t.Fatal("Expected the most visits selector by default")
+	}
+	if best := tree.BestMove(); best != 2 {
+		t.Fatalf("Expected the most visited move, got %d", best)
+	}
+
+	tree.SetBestChildSelector(NewLCBSelector[Move, *NodeStats](1.96))
+	if best := tree.BestMove(); best != 3 {
+		t.Fatalf("Expected the LCB move, got %d", best)
+	}
+	if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != 2 {
+		t.Fatalf("Expected the explicit policy to ignore the selector, got %d", best.Move)
+	}
+
+	// Lines ordered by the selector, the never chosen ones by the visits
+	tree.Limiter.SetLimits(DefaultLimits().SetMultiPv(4))
+	lines := tree.MultiPv(BestChildDefault)
+	expected := []Move{3, 1, 2, 0}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i, line := range lines {
+		if line.Root.Move != expected[i] {
+			t.Fatalf("Line %d: expected move %d, got %d", i, expected[i], line.Root.Move)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package mcts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Single-Player MCTS (SP-MCTS), for puzzles and optimization problems
+// Reference: https://dke.maastrichtuniversity.nl/m.winands/documents/CGSameGame.pdf
+//
+// There is no opponent, so the results are never flipped during the backpropagation,
+// nodes track the best score seen besides the mean, and the selection adds
+// a variance term to the UCT formula. Scores must be normalized to [0, 1].
+
+type SinglePlayerStatsLike[S any] interface {
+	VarianceStatsLike[S]
+
+	// Best result seen in this node
+	Best() Result
+	// Sets the best result if given one is better, returns true if it was updated
+	UpdateBest(Result) bool
+}
+
+// Node statistics with the sum of squares and the best result
+type SinglePlayerStats struct {
+	VarianceStats
+
+	// float64 bits of the best result
+	best uint64
+}
+
+func DefaultSinglePlayerStats() *SinglePlayerStats {
+	return &SinglePlayerStats{}
+}
+
+func (s *SinglePlayerStats) Clone() *SinglePlayerStats {
+	return &SinglePlayerStats{
+		VarianceStats: *s.VarianceStats.Clone(),
+		best:          atomic.LoadUint64(&s.best),
+	}
+}
+
+func (s *SinglePlayerStats) Best() Result {
+	return Result(math.Float64frombits(atomic.LoadUint64(&s.best)))
+}
+
+func (s *SinglePlayerStats) UpdateBest(result Result) bool {
+	for {
+		old := atomic.LoadUint64(&s.best)
+		if float64(result) <= math.Float64frombits(old) {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&s.best, old, math.Float64bits(float64(result))) {
+			return true
+		}
+	}
+}
+
+// Encodes the node counters, sum of squares and the best result, implements encoding.BinaryMarshaler
+func (s *SinglePlayerStats) MarshalBinary() ([]byte, error) {
+	buf := s.VarianceStats.appendBinary(make([]byte, 0, varianceStatsBinarySize+8))
+	return binary.LittleEndian.AppendUint64(buf, atomic.LoadUint64(&s.best)), nil
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (s *SinglePlayerStats) UnmarshalBinary(data []byte) error {
+	if len(data) < varianceStatsBinarySize+8 {
+		return fmt.Errorf("[MCTS] SinglePlayerStats: expected %d bytes, got %d", varianceStatsBinarySize+8, len(data))
+	}
+
+	if err := s.VarianceStats.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&s.best, binary.LittleEndian.Uint64(data[varianceStatsBinarySize:]))
+	return nil
+}
+
+// Stats with the best result seen, used by BestChildMaxScore policy
+type bestScoreStats interface {
+	Best() Result
+}
+
+// Result of the single-player playout, with the moves played in the rollout
+type SinglePlayerResult[T MoveLike] interface {
+	// Score of the whole game, in range [0, 1]
+	Value() Result
+	// Moves played in the rollout (starting from the leaf)
+	Moves() []T
+}
+
+// Simple SinglePlayerResult implementation
+type Playout[T MoveLike] struct {
+	Score    Result
+	Sequence []T
+}
+
+func (p Playout[T]) Value() Result {
+	return p.Score
+}
+
+func (p Playout[T]) Moves() []T {
+	return p.Sequence
+}
+
+// Best complete sequence found during the search
+type sequenceRecord[T MoveLike, S NodeStatsLike[S]] struct {
+	mx    sync.Mutex
+	score Result
+	leaf  *NodeBase[T, S]
+	moves []T
+}
+
+// Strategies remembering the best sequence, used by Pv with BestChildMaxScore policy
+type bestSequenceStrategy[T MoveLike, S NodeStatsLike[S]] interface {
+	BestSequence() (leaf *NodeBase[T, S], rollout []T)
+	// Forgets the sequence, unless it's in the subtree of the new 'root' ('previous' is where
+	// the root was before MakeMove, the pool may move it), nil root forgets it always
+	RetainBestSequence(root, previous *NodeBase[T, S])
+}
+
+type SPMCTS[T MoveLike, S SinglePlayerStatsLike[S], R SinglePlayerResult[T], O GameOperations[T, S, R, O]] struct {
+	// Exploration constant C of the UCT term
+	ExplorationParam float64
+	// Constant D of the variance term, high values favour nodes with few visits
+	VarianceParam float64
+	best          sequenceRecord[T, S]
+}
+
+func NewSPMCTS[T MoveLike, S SinglePlayerStatsLike[S], R SinglePlayerResult[T], O GameOperations[T, S, R, O]](c, d float64) *SPMCTS[T, S, R, O] {
+	return &SPMCTS[T, S, R, O]{
+		ExplorationParam: c,
+		VarianceParam:    d,
+	}
+}
+
+func (sp *SPMCTS[T, S, R, O]) SetExplorationParam(c float64) *SPMCTS[T, S, R, O] {
+	sp.ExplorationParam = max(0, c)
+	return sp
+}
+
+func (sp *SPMCTS[T, S, R, O]) SetVarianceParam(d float64) *SPMCTS[T, S, R, O] {
+	sp.VarianceParam = max(0, d)
+	return sp
+}
+
+func (sp *SPMCTS[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := math.Inf(-1)
+	index := 0
+	lnParentVisits := math.Log(float64(parent.Stats.N()))
+	var child *NodeBase[T, S]
+	var actualVisits, visits, vl int32
+
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
+		child = &children[i]
+		visits, vl = child.Stats.GetVvl()
+		actualVisits = visits - vl
+
+		// Pick the unvisited one
+		if actualVisits == 0 {
+			return child
+		}
+
+		// SP-MCTS: mean + C * sqrt(ln(parent_visits)/visits) + sqrt((sum_sq - n * mean^2 + D) / n)
+		q := float64(child.Stats.Q())
+		mean := q / float64(actualVisits)
+		variance := float64(child.Stats.SumSquares()) - float64(actualVisits)*mean*mean + sp.VarianceParam
+
+		uct := q/float64(visits) +
+			sp.ExplorationParam*math.Sqrt(lnParentVisits/float64(visits)) +
+			math.Sqrt(max(0, variance)/float64(visits))
+
+		if uct > best {
+			best = uct
+			index = i
+		}
+	}
+
+	return &children[index]
+}
+
+func (sp *SPMCTS[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result R) {
+	v := result.Value()
+	leaf := node
+
+	for node != nil {
+
+		// Reverse virtual loss for non-root
+		if node.Parent != nil {
+			node.Stats.AddVvl(1-VirtualLoss, -VirtualLoss)
+		} else {
+			node.Stats.AddVvl(1, 0)
+		}
+
+		// No perspective switching, there is only one player
+		node.Stats.AddQ(v)
+		node.Stats.AddSquare(v)
+		if node.Stats.UpdateBest(v) && node.Parent == nil {
+			sp.storeBest(leaf, v, result.Moves())
+		}
+
+		node = node.Parent
+		ops.BackTraverse()
+	}
+}
+
+// Remember the best sequence, if it's better than the current one
+func (sp *SPMCTS[T, S, R, O]) storeBest(leaf *NodeBase[T, S], score Result, moves []T) {
+	sp.best.mx.Lock()
+	defer sp.best.mx.Unlock()
+
+	if sp.best.leaf == nil || score >= sp.best.score {
+		sp.best.score = score
+		sp.best.leaf = leaf
+		sp.best.moves = slices.Clone(moves)
+	}
+}
+
+// Leaf of the best sequence found and the rollout moves played from it
+func (sp *SPMCTS[T, S, R, O]) BestSequence() (*NodeBase[T, S], []T) {
+	sp.best.mx.Lock()
+	defer sp.best.mx.Unlock()
+	return sp.best.leaf, sp.best.moves
+}
+
+func (sp *SPMCTS[T, S, R, O]) RetainBestSequence(root, previous *NodeBase[T, S]) {
+	sp.best.mx.Lock()
+	defer sp.best.mx.Unlock()
+
+	for node := sp.best.leaf; node != nil; node = node.Parent {
+		if node == root || node == previous {
+			if sp.best.leaf == previous {
+				sp.best.leaf = root
+			}
+			return
+		}
+	}
+
+	// Stale score would block the sequences of the new root
+	sp.best.score = 0
+	sp.best.leaf = nil
+	sp.best.moves = nil
+}
+
+// Score of the best sequence found
+func (sp *SPMCTS[T, S, R, O]) BestScore() Result {
+	sp.best.mx.Lock()
+	defer sp.best.mx.Unlock()
+	return sp.best.score
+}
+
+// Drops the remembered best sequence outside of the new 'root' subtree, called whenever the root changes
+func (mcts *MCTS[T, S, R, O, A]) retainBestSequence(root, previous *NodeBase[T, S]) {
+	if provider, ok := any(mcts.strategy).(bestSequenceStrategy[T, S]); ok {
+		provider.RetainBestSequence(root, previous)
+	}
+}
+
+// Returns the best complete sequence starting at 'root' (tree moves + rollout moves),
+// false if the strategy doesn't remember sequences, or the best one isn't in the 'root' subtree
+// (for example after MakeMove)
+func (mcts *MCTS[T, S, R, O, A]) bestSequence(root *NodeBase[T, S], includeRoot bool) ([]T, bool) {
+	provider, ok := any(mcts.strategy).(bestSequenceStrategy[T, S])
+	if !ok {
+		return nil, false
+	}
+
+	leaf, rollout := provider.BestSequence()
+	if leaf == nil {
+		return nil, false
+	}
+
+	moves := make([]T, 0, len(rollout)+mcts.MaxDepth()+1)
+	node := leaf
+	for ; node != nil && node != root; node = node.Parent {
+		moves = append(moves, node.Move)
+	}
+
+	if node != root {
+		return nil, false
+	}
+
+	if includeRoot {
+		moves = append(moves, root.Move)
+	}
+	slices.Reverse(moves)
+	return append(moves, rollout...), true
+}
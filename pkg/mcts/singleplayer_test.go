@@ -0,0 +1,154 @@
+package mcts
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Puzzle: guess the 'spTarget' sequence, the score is the fraction of correctly guessed moves
+var spTarget = []Move{2, 0, 3, 1, 2}
+
+const spBranchFactor = 4
+
+type PuzzleOps struct {
+	history []Move
+	rand    *rand.Rand
+	base    int // moves made with MakeMove, clones don't go back past them
+}
+
+func (o *PuzzleOps) Reset()          {}
+func (o *PuzzleOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *PuzzleOps) BackTraverse() {
+	if len(o.history) > o.base {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *PuzzleOps) ExpandNode(parent *NodeBase[Move, *SinglePlayerStats]) uint32 {
+	if len(o.history) >= len(spTarget) {
+		return 0
+	}
+
+	terminal := len(o.history)+1 >= len(spTarget)
+	parent.Children = make([]NodeBase[Move, *SinglePlayerStats], spBranchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), terminal, &SinglePlayerStats{})
+	}
+	return spBranchFactor
+}
+
+func (o *PuzzleOps) Rollout() Playout[Move] {
+	rollout := make([]Move, 0, len(spTarget))
+	for len(o.history)+len(rollout) < len(spTarget) {
+		rollout = append(rollout, Move(o.rand.Intn(spBranchFactor)))
+	}
+
+	correct := 0
+	for i, m := range append(slices.Clone(o.history), rollout...) {
+		if m == spTarget[i] {
+			correct++
+		}
+	}
+	return Playout[Move]{Score: Result(correct) / Result(len(spTarget)), Sequence: rollout}
+}
+
+func (o *PuzzleOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *PuzzleOps) Clone() *PuzzleOps {
+	return &PuzzleOps{history: slices.Clone(o.history), base: len(o.history)}
+}
+
+func newPuzzleMCTS() *MCTS[Move, *SinglePlayerStats, Playout[Move], *PuzzleOps, *SPMCTS[Move, *SinglePlayerStats, Playout[Move], *PuzzleOps]] {
+	return NewMTCS(
+		NewSPMCTS[Move, *SinglePlayerStats, Playout[Move], *PuzzleOps](0.5, 0.01),
+		&PuzzleOps{},
+		MultithreadTreeParallel,
+		&SinglePlayerStats{},
+	)
+}
+
+func TestSinglePlayerSearch(t *testing.T) {
+	tree := newPuzzleMCTS()
+	tree.SetLimits(DefaultLimits().SetCycles(3000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	pv, terminal, _ := tree.Pv(tree.Root, BestChildMaxScore, false)
+	if !slices.Equal(pv, spTarget) || !terminal {
+		t.Fatalf("Expected best sequence %v, got %v (terminal %v)", spTarget, pv, terminal)
+	}
+
+	if score := tree.Strategy().BestScore(); score != 1 {
+		t.Fatalf("Expected best score 1, got %f", score)
+	}
+
+	// Results are not flipped, so the best child has the best mean as well
+	best := tree.BestChild(tree.Root, BestChildMaxScore)
+	if best.Move != spTarget[0] || best.Stats.Best() != 1 {
+		t.Fatalf("Expected best child %d with score 1, got %d (%f)", spTarget[0], best.Move, best.Stats.Best())
+	}
+
+	if tree.RootScore() <= 0.5 {
+		t.Fatalf("Expected root score > 0.5, got %f", tree.RootScore())
+	}
+}
+
+func TestSinglePlayerSequenceAfterMakeMove(t *testing.T) {
+	tree := newPuzzleMCTS()
+	tree.SetLimits(DefaultLimits().SetCycles(3000))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Best sequence still valid in the subtree
+	tree.MakeMove(spTarget[0])
+	if pv, _, _ := tree.Pv(tree.Root, BestChildMaxScore, false); !slices.Equal(pv, spTarget[1:]) {
+		t.Fatalf("Expected best sequence %v, got %v", spTarget[1:], pv)
+	}
+
+	// Best sequence is not in the subtree, falls back to the tree path
+	tree.MakeMove(spTarget[1] + 1)
+	if pv, _, _ := tree.Pv(tree.Root, BestChildMaxScore, false); len(pv) > len(spTarget)-2 {
+		t.Fatalf("Unexpected pv after leaving the best sequence: %v", pv)
+	}
+}
+
+func TestSinglePlayerSequenceNewRoot(t *testing.T) {
+	for _, pooled := range []bool{false, true} {
+		tree := newPuzzleMCTS()
+		if pooled {
+			tree.SetNodePool(NewNodePool[Move, SinglePlayerStats](64))
+		}
+		tree.SetLimits(DefaultLimits().SetCycles(3000))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		// Kept in the subtree, the pool moves the new root
+		tree.MakeMove(spTarget[0])
+		if leaf, _ := tree.Strategy().BestSequence(); tree.Strategy().BestScore() != 1 || leaf == nil {
+			t.Fatalf("Pool %v: expected the best sequence to be kept", pooled)
+		}
+
+		// Sequence of the old root is dropped, 4 out of 5 moves can be guessed now
+		tree.MakeMove(spTarget[1] + 1)
+		if leaf, _ := tree.Strategy().BestSequence(); leaf != nil || tree.Strategy().BestScore() != 0 {
+			t.Fatalf("Pool %v: expected the best sequence to be dropped", pooled)
+		}
+
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+		if score := tree.Strategy().BestScore(); score != 0.8 {
+			t.Fatalf("Pool %v: expected best score 0.8, got %f", pooled, score)
+		}
+		if pv, _, _ := tree.Pv(tree.Root, BestChildMaxScore, false); !slices.Equal(pv, spTarget[2:]) {
+			t.Fatalf("Pool %v: expected best sequence %v, got %v", pooled, spTarget[2:], pv)
+		}
+
+		tree.Reset(false, &SinglePlayerStats{})
+		if leaf, _ := tree.Strategy().BestSequence(); leaf != nil || tree.Strategy().BestScore() != 0 {
+			t.Fatalf("Pool %v: expected the best sequence to be dropped on Reset", pooled)
+		}
+	}
+}
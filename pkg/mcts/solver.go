@@ -0,0 +1,267 @@
+package mcts
+
+import "sync/atomic"
+
+// MCTS-Solver, proves game-theoretic values of the nodes
+// Reference: https://dke.maastrichtuniversity.nl/m.winands/documents/uctloa.pdf
+//
+// Terminal leaves get their exact value from the playout result, then the proofs
+// are propagated up the tree (for 2 player zero-sum games):
+//   - a node is a proven loss (for the player that moved into it), if any child is a proven win
+//   - a node is a proven win, if all children are proven losses
+//   - a node is a proven draw, if all children are proven, and the best of them is a draw
+//   - a chance node is proven, if all outcomes are proven with the same value
+//
+// Like the stats, the proof of the node is from the perspective of the player that moved into it.
+// Strategies skip children that are proven losses, and the search stops once the root is solved.
+
+type ProofState uint8
+
+const (
+	ProofUnknown ProofState = iota
+	ProofWin
+	ProofLoss
+	ProofDraw
+)
+
+func (p ProofState) String() string {
+	switch p {
+	case ProofWin:
+		return "Win"
+	case ProofLoss:
+		return "Loss"
+	case ProofDraw:
+		return "Draw"
+	}
+	return "Unknown"
+}
+
+// Proof is stored in a single uint32: state in 2 lowest bits, and the number
+// of plies to the end of the game in the rest
+const (
+	proofStateBits = 2
+	proofStateMask = 1<<proofStateBits - 1
+)
+
+func packProof(state ProofState, plies int) uint32 {
+	return uint32(plies)<<proofStateBits | uint32(state)
+}
+
+// Returns the proof state of the node and the number of plies to the end of the game
+// (0 for terminal nodes), assuming optimal play
+func (node *NodeBase[T, S]) Proof() (ProofState, int) {
+	proof := atomic.LoadUint32(&node.proof)
+	return ProofState(proof & proofStateMask), int(proof >> proofStateBits)
+}
+
+func (node *NodeBase[T, S]) ProofState() ProofState {
+	return ProofState(atomic.LoadUint32(&node.proof) & proofStateMask)
+}
+
+// Whether the game-theoretic value of the node is known
+func (node *NodeBase[T, S]) Proven() bool {
+	return node.ProofState() != ProofUnknown
+}
+
+// Player that moved into this node wins
+func (node *NodeBase[T, S]) ProvenWin() bool {
+	return node.ProofState() == ProofWin
+}
+
+// Player that moved into this node loses
+func (node *NodeBase[T, S]) ProvenLoss() bool {
+	return node.ProofState() == ProofLoss
+}
+
+// Sets the proof, if it wasn't set yet, returns true on success
+func (node *NodeBase[T, S]) setProof(state ProofState, plies int) bool {
+	return atomic.CompareAndSwapUint32(&node.proof, 0, packProof(state, plies))
+}
+
+// Enables (or disables) the solver, results of the terminal playouts must be
+// exactly 0, 0.5 or 1 (Result or GameResult with Value() method). Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetSolver(enabled bool) {
+	mcts.solver = enabled
+}
+
+func (mcts *MCTS[T, S, R, O, A]) Solver() bool {
+	return mcts.solver
+}
+
+// Exact value of the proven node, from the perspective of the player that moved into it
+func proofValue(state ProofState) (Result, bool) {
+	switch state {
+	case ProofWin:
+		return 1, true
+	case ProofLoss:
+		return 0, true
+	case ProofDraw:
+		return 0.5, true
+	}
+	return 0, false
+}
+
+// Float value of the game result
+func resultValue[R GameResult](result R) (Result, bool) {
+	switch r := any(result).(type) {
+	case Result:
+		return r, true
+	case interface{ Value() Result }:
+		return r.Value(), true
+	}
+	return 0, false
+}
+
+// Combines the proofs of the node's children, returns ProofUnknown if the node can't be proven yet.
+// If more children may be added ('complete' is false, see SetProgressiveWidening), only a loss can be proven.
+func provenFromChildren[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S], complete bool) (ProofState, int) {
+	if !node.Expanded() {
+		return ProofUnknown, 0
+	}
+
+	children := node.VisibleChildren()
+	if len(children) == 0 {
+		return ProofUnknown, 0
+	}
+
+	if node.Chance() {
+		return provenFromOutcomes(node)
+	}
+
+	allLosses := true
+	winPlies, lossPlies, drawPlies := -1, 0, -1
+	for i := range children {
+		state, plies := children[i].Proof()
+		switch state {
+		case ProofUnknown:
+			// Can still be proven a loss, if there is a winning child
+			allLosses = false
+			drawPlies = -2
+		case ProofWin:
+			// The player to move wins, choosing the quickest win
+			if winPlies == -1 || plies < winPlies {
+				winPlies = plies
+			}
+		case ProofLoss:
+			// Delay the loss as long as possible
+			lossPlies = max(lossPlies, plies)
+		case ProofDraw:
+			allLosses = false
+			if drawPlies != -2 {
+				drawPlies = max(drawPlies, plies)
+			}
+		}
+	}
+
+	switch {
+	case winPlies != -1:
+		return ProofLoss, winPlies + 1
+	case !complete:
+		return ProofUnknown, 0
+	case allLosses:
+		return ProofWin, lossPlies + 1
+	case drawPlies >= 0:
+		return ProofDraw, drawPlies + 1
+	}
+	return ProofUnknown, 0
+}
+
+// Chance node is proven only if every outcome has the same value (from the same perspective)
+func provenFromOutcomes[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S]) (ProofState, int) {
+	state, plies := node.Children[0].Proof()
+	for i := 1; i < len(node.Children) && state != ProofUnknown; i++ {
+		childState, childPlies := node.Children[i].Proof()
+		if childState != state {
+			return ProofUnknown, 0
+		}
+		plies = max(plies, childPlies)
+	}
+
+	if state == ProofUnknown {
+		return ProofUnknown, 0
+	}
+	return state, plies + 1
+}
+
+// Exact result of the proven, non-terminal leaf, from the perspective of the side to move
+// in that leaf (same as the playout). Only possible if R is the Result itself.
+func (mcts *MCTS[T, S, R, O, A]) provenResult(leaf *NodeBase[T, S]) (R, bool) {
+	value, ok := proofValue(leaf.ProofState())
+	if !ok {
+		var zero R
+		return zero, false
+	}
+
+	result, ok := any(1 - value).(R)
+	return result, ok
+}
+
+// Proves the terminal 'leaf' with the playout 'result' and propagates the proofs up the tree
+func (mcts *MCTS[T, S, R, O, A]) solve(leaf *NodeBase[T, S], result R) {
+	if leaf.Terminal() && !leaf.Proven() {
+		v, ok := resultValue(result)
+		if !ok {
+			return
+		}
+
+		// Result is from the side to move perspective, proof from the one that moved into the leaf
+		switch 1 - v {
+		case 1:
+			leaf.setProof(ProofWin, 0)
+		case 0:
+			leaf.setProof(ProofLoss, 0)
+		case 0.5:
+			leaf.setProof(ProofDraw, 0)
+		default:
+			return
+		}
+	}
+
+	if leaf.Proven() {
+		mcts.proveAncestors(leaf.Parent)
+	}
+}
+
+// Proves the 'node' and its ancestors from the proofs of their children, stops at the first unproven one
+func (mcts *MCTS[T, S, R, O, A]) proveAncestors(node *NodeBase[T, S]) {
+	for ; node != nil; node = node.Parent {
+		state, plies := provenFromChildren(node, mcts.childrenComplete(node))
+		if state == ProofUnknown || !node.setProof(state, plies) {
+			return
+		}
+
+		if node.Parent == nil {
+			mcts.Limiter.SetSolved(true)
+		}
+	}
+}
+
+// Picks the child based on the proofs: the quickest win, or the longest loss if every
+// child loses (and no more children can be added, see 'complete'), nil if the proofs don't decide
+func provenBestChild[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S], complete bool) *NodeBase[T, S] {
+	var win, loss *NodeBase[T, S]
+	winPlies, lossPlies := -1, -1
+
+	children := node.VisibleChildren()
+	for i := range children {
+		child := &children[i]
+		state, plies := child.Proof()
+		switch state {
+		case ProofWin:
+			if winPlies == -1 || plies < winPlies {
+				win, winPlies = child, plies
+			}
+		case ProofLoss:
+			if plies > lossPlies {
+				loss, lossPlies = child, plies
+			}
+		default:
+			loss, lossPlies = nil, int(^uint(0)>>1)
+		}
+	}
+
+	if win != nil || !complete {
+		return win
+	}
+	return loss
+}
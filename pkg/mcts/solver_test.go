@@ -0,0 +1,146 @@
+package mcts
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// Nim with a single pile, players take 1 or 2 stones, the one taking the last stone wins.
+// Positions with the pile divisible by 3 are lost for the side to move.
+type NimOps struct {
+	pile    int
+	history []Move
+	rand    *rand.Rand
+}
+
+func (o *NimOps) Reset() {}
+func (o *NimOps) Traverse(m Move) {
+	o.history = append(o.history, m)
+	o.pile -= int(m)
+}
+func (o *NimOps) BackTraverse() {
+	if len(o.history) > 0 {
+		o.pile += int(o.history[len(o.history)-1])
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *NimOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if o.pile == 0 {
+		return 0
+	}
+
+	parent.Children = make([]NodeBase[Move, *NodeStats], 0, 2)
+	for take := 1; take <= min(2, o.pile); take++ {
+		parent.Children = append(parent.Children, *NewBaseNode(parent, Move(take), o.pile == take, &NodeStats{}))
+	}
+	return uint32(len(parent.Children))
+}
+
+// 1 if the side to move wins, 0 otherwise
+func (o *NimOps) Rollout() Result {
+	pile, turn := o.pile, 0
+	for pile > 0 {
+		pile -= min(pile, 1+o.rand.Intn(2))
+		turn ^= 1
+	}
+
+	// The side that couldn't move lost
+	if turn == 0 {
+		return 0
+	}
+	return 1
+}
+
+func (o *NimOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *NimOps) Clone() *NimOps {
+	return &NimOps{pile: o.pile, history: append([]Move(nil), o.history...)}
+}
+
+func newNimMCTS(pile int) *MCTS[Move, *NodeStats, Result, *NimOps, *UCB1[Move, *NodeStats, Result, *NimOps]] {
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *NimOps](0.7),
+		&NimOps{pile: pile},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetSolver(true)
+	return tree
+}
+
+func TestSolverProvesWin(t *testing.T) {
+	tree := newNimMCTS(7)
+	tree.SetLimits(DefaultLimits().SetCycles(1000000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if tree.StopReason()&StopSolved == 0 {
+		t.Fatalf("Expected the search to stop with StopSolved, got %v", tree.StopReason())
+	}
+
+	// Root's proof is from the perspective of the enemy
+	if !tree.Root.ProvenLoss() {
+		t.Fatalf("Expected the root to be a proven loss for the enemy, got %v", tree.Root.ProofState())
+	}
+
+	best := tree.BestChild(tree.Root, BestChildMostVisits)
+	if best.Move != 1 || !best.ProvenWin() {
+		t.Fatalf("Expected proven winning move 1, got %d (%v)", best.Move, best.ProofState())
+	}
+
+	if score := tree.RootScore(); score != 1 {
+		t.Fatalf("Expected root score 1, got %f", score)
+	}
+
+	// 7 stones, winning side takes 1 and then always 3 - enemy's move: 1 + 2 + 2 = 5 plies
+	pv := tree.MultiPv(BestChildMostVisits)
+	if pv[0].Proof != ProofWin || pv[0].ProofPlies != 5 {
+		t.Fatalf("Expected win in 5 plies, got %v in %d", pv[0].Proof, pv[0].ProofPlies)
+	}
+}
+
+func TestSolverProvesLoss(t *testing.T) {
+	tree := newNimMCTS(6)
+	tree.SetLimits(DefaultLimits().SetCycles(1000000).SetThreads(1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if !tree.Root.ProvenWin() {
+		t.Fatalf("Expected the root to be a proven win for the enemy, got %v", tree.Root.ProofState())
+	}
+
+	if score := tree.RootScore(); score != 0 {
+		t.Fatalf("Expected root score 0, got %f", score)
+	}
+
+	// Proofs are kept in the saved tree
+	buf := &bytes.Buffer{}
+	if err := tree.Save(buf, BinaryMoveCodec[Move]{}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := newNimMCTS(6)
+	if err := loaded.Load(buf, BinaryMoveCodec[Move]{}, &NodeStats{}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if !deepCompare(tree.Root, loaded.Root) {
+		t.Fatal("Loaded tree differs from the saved one")
+	}
+}
+
+func TestSolverDisabled(t *testing.T) {
+	tree := newNimMCTS(7)
+	tree.SetSolver(false)
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if tree.Root.Proven() || tree.StopReason()&StopSolved != 0 {
+		t.Fatal("Expected no proofs with the solver disabled")
+	}
+}
@@ -1,6 +1,7 @@
 package mcts
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sync/atomic"
 )
@@ -110,3 +111,27 @@ func (stats *NodeStats) SetVvl(visits, virtualLoss int32) {
 		panic(fmt.Sprintf("Virtual loss (%d) cannot be greater than visits (%d)", virtualLoss, visits))
 	}
 }
+
+// Size of the NodeStats binary encoding
+const nodeStatsBinarySize = 12
+
+// Encodes the outcomes and visits (without the virtual loss), implements encoding.BinaryMarshaler
+func (stats *NodeStats) MarshalBinary() ([]byte, error) {
+	return stats.appendBinary(make([]byte, 0, nodeStatsBinarySize)), nil
+}
+
+func (stats *NodeStats) appendBinary(buf []byte) []byte {
+	buf = binary.LittleEndian.AppendUint64(buf, stats.RawQ())
+	return binary.LittleEndian.AppendUint32(buf, uint32(stats.RealVisits()))
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (stats *NodeStats) UnmarshalBinary(data []byte) error {
+	if len(data) < nodeStatsBinarySize {
+		return fmt.Errorf("[MCTS] NodeStats: expected %d bytes, got %d", nodeStatsBinarySize, len(data))
+	}
+
+	atomic.StoreUint64(&stats.q, binary.LittleEndian.Uint64(data))
+	stats.SetVvl(int32(binary.LittleEndian.Uint32(data[8:])), 0)
+	return nil
+}
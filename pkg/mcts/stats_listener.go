@@ -6,6 +6,9 @@ type SearchLine[T MoveLike] struct {
 	Eval     float64
 	Terminal bool
 	Draw     bool
+	// Proven value and the number of plies to the end of the game (see PvResult)
+	Proof      ProofState
+	ProofPlies int
 }
 
 type ListenerTreeStats[T MoveLike] struct {
@@ -21,20 +24,25 @@ type ListenerTreeStats[T MoveLike] struct {
 // Convert TreeStats to 'ListenerTreeStats' struct
 func toListenerStats[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O], A StrategyLike[T, S, R, O]](
 	tree *MCTS[T, S, R, O, A]) ListenerTreeStats[T] {
-	pv := tree.MultiPv(BestChildMostVisits)
+	pv := tree.MultiPv(BestChildDefault)
 	lines := make([]SearchLine[T], len(pv))
 	for i := range len(pv) {
 		avq := float64(pv[i].Root.Stats.Q())
 		if pv[i].Root.Stats.N() > 0 {
 			avq /= float64(pv[i].Root.Stats.N())
 		}
+		if value, ok := proofValue(pv[i].Proof); ok {
+			avq = float64(value)
+		}
 
 		lines[i] = SearchLine[T]{
-			BestMove: pv[i].Root.Move,
-			Moves:    pv[i].Pv,
-			Eval:     avq,
-			Terminal: pv[i].Terminal,
-			Draw:     pv[i].Draw,
+			BestMove:   pv[i].Root.Move,
+			Moves:      pv[i].Pv,
+			Eval:       avq,
+			Terminal:   pv[i].Terminal,
+			Draw:       pv[i].Draw,
+			Proof:      pv[i].Proof,
+			ProofPlies: pv[i].ProofPlies,
 		}
 	}
 
@@ -1,10 +1,32 @@
 package mcts
 
+import "math/rand"
+
 type StrategyLike[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] interface {
 	Select(node, root *NodeBase[T, S]) *NodeBase[T, S]
 	Backpropagate(ops O, node *NodeBase[T, S], result R)
 }
 
+// State of the search thread during the selection
+type SelectionContext struct {
+	Rand     *rand.Rand // random generator of the search thread (see RandGameOperations)
+	ThreadId int        // index of the search thread, 0 is the main one
+	Depth    int32      // depth of the node the child is selected for, 0 for the root
+}
+
+// Strategy which needs the search thread's state (see ThompsonSampling),
+// the search calls SelectContext instead of Select
+type ContextStrategy[T MoveLike, S NodeStatsLike[S]] interface {
+	SelectContext(node, root *NodeBase[T, S], ctx SelectionContext) *NodeBase[T, S]
+}
+
+// Strategy backpropagating the results of several simulations of the same leaf at once
+// (see MultithreadLeafParallel), 'sum' is the sum of 'count' results, from the same
+// perspective as the result of Backpropagate
+type BatchBackpropagator[T MoveLike, S NodeStatsLike[S], O any] interface {
+	BackpropagateBatch(ops O, node *NodeBase[T, S], sum Result, count int32)
+}
+
 type DefaultBackprop[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct{}
 
 // Assumes the game is 2 player and zero sum, meaning for given result for the current player,
@@ -34,8 +56,41 @@ func (b DefaultBackprop[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S],
 		// Add the outcome
 		node.Stats.AddQ(result)
 
+		// Chance node shares the perspective of its outcomes (see ChanceGameOperations)
+		if node.Parent != nil && node.Parent.Chance() {
+			result = 1.0 - result
+		}
+
 		// Backpropagate
 		node = node.Parent
 		ops.BackTraverse()
 	}
 }
+
+func (b DefaultBackprop[T, S, R, O]) BackpropagateBatch(ops O, node *NodeBase[T, S], sum Result, count int32) {
+	backpropagateSum(ops, node, sum, count)
+}
+
+// Two-player zero-sum backpropagation (see DefaultBackprop) of 'count' results at once, their sum is 'sum'
+func backpropagateSum[T MoveLike, S NodeStatsLike[S]](ops interface{ BackTraverse() }, node *NodeBase[T, S], sum Result, count int32) {
+	for node != nil {
+
+		// Reverse virtual loss (applied once by the selection) for non-root
+		if node.Parent != nil {
+			node.Stats.AddVvl(count-VirtualLoss, -VirtualLoss)
+		} else {
+			node.Stats.AddVvl(count, 0)
+		}
+
+		sum = Result(count) - sum // switch the results
+		node.Stats.AddQ(sum)
+
+		// Chance node shares the perspective of its outcomes (see ChanceGameOperations)
+		if node.Parent != nil && node.Parent.Chance() {
+			sum = Result(count) - sum
+		}
+
+		node = node.Parent
+		ops.BackTraverse()
+	}
+}
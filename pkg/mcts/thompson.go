@@ -0,0 +1,82 @@
+package mcts
+
+import "math/rand"
+
+// Thompson sampling, Bayesian selection
+// Reference: Bai et al., Bayesian Mixture Modelling and Inference based Thompson Sampling in Monte-Carlo Tree Search (NIPS 2013)
+//
+// Every child keeps a Beta(Prior + wins, Prior + losses) posterior of its value, where the wins are
+// the sum of the results (a draw is half a win, see NodeStats) and the losses the rest of the visits.
+// The selection draws a sample from each posterior, and picks the child with the best one.
+// Samples use the search thread's generator (see ContextStrategy), so the threads of the tree-parallel
+// search naturally pick different children, and collide less. Results must be in [0, 1].
+
+type ThompsonSampling[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	DefaultBackprop[T, S, R, O]
+	// Pseudo-counts of the wins and losses before any visit, 1 is the uniform prior
+	Prior float64
+	// Generator of the Select calls outside of the search
+	rand sampler
+}
+
+// 'prior' is the number of pseudo-wins and pseudo-losses, must be > 0
+func NewThompsonSampling[T MoveLike, S NodeStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](prior float64) *ThompsonSampling[T, S, R, O] {
+	ts := &ThompsonSampling[T, S, R, O]{}
+	return ts.SetPrior(prior)
+}
+
+func (ts *ThompsonSampling[T, S, R, O]) SetPrior(prior float64) *ThompsonSampling[T, S, R, O] {
+	if prior <= 0 {
+		panic("[MCTS] ThompsonSampling.SetPrior: prior must be positive")
+	}
+	ts.Prior = prior
+	return ts
+}
+
+// Same as SelectContext, but with a shared generator (slow), the search never calls it
+func (ts *ThompsonSampling[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	r := ts.rand.lock()
+	defer ts.rand.unlock()
+	return ts.sample(parent, r)
+}
+
+func (ts *ThompsonSampling[T, S, R, O]) SelectContext(parent, root *NodeBase[T, S], ctx SelectionContext) *NodeBase[T, S] {
+	return ts.sample(parent, ctx.Rand)
+}
+
+func (ts *ThompsonSampling[T, S, R, O]) sample(parent *NodeBase[T, S], r *rand.Rand) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := -1.0
+	index := 0
+	children := parent.VisibleChildren()
+	for i := range children {
+		child := &children[i]
+		if child.ProvenLoss() {
+			// No point in choosing a losing move (see MCTS.SetSolver)
+			continue
+		}
+
+		// Virtual loss visits count as losses, steering the other threads away
+		wins := float64(child.Stats.Q())
+		losses := max(0, float64(child.Stats.N())-wins)
+		if value := betaSample(r, ts.Prior+wins, ts.Prior+losses); value > best {
+			best = value
+			index = i
+		}
+	}
+
+	return &children[index]
+}
+
+// Sample of the Beta(a, b) distribution
+func betaSample(r *rand.Rand, a, b float64) float64 {
+	x := gammaSample(r, a)
+	y := gammaSample(r, b)
+	if x+y == 0 {
+		return 0.5
+	}
+	return x / (x + y)
+}
@@ -0,0 +1,87 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// UCB1, recording the selection contexts it was given
+type contextRecorder struct {
+	UCB1[Move, *NodeStats, Result, *DummyOps]
+	mx       sync.Mutex
+	threads  map[int]bool
+	maxDepth int32
+	noRand   bool
+}
+
+func (c *contextRecorder) SelectContext(node, root *NodeBase[Move, *NodeStats], ctx SelectionContext) *NodeBase[Move, *NodeStats] {
+	c.mx.Lock()
+	c.threads[ctx.ThreadId] = true
+	c.maxDepth = max(c.maxDepth, ctx.Depth)
+	c.noRand = c.noRand || ctx.Rand == nil || (node == root) != (ctx.Depth == 0)
+	c.mx.Unlock()
+	return c.Select(node, root)
+}
+
+func TestSelectionContext(t *testing.T) {
+	strategy := &contextRecorder{UCB1: UCB1[Move, *NodeStats, Result, *DummyOps]{ExplorationParam: 0.45}, threads: map[int]bool{}}
+	tree := NewMTCS(strategy, &DummyOps{}, MultithreadTreeParallel, &NodeStats{})
+	// Long enough for every thread to be scheduled, even on a single busy core
+	tree.SetLimits(DefaultLimits().SetMovetime(200).SetThreads(3))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if len(strategy.threads) != 3 {
+		t.Fatalf("Expected the contexts of 3 threads, got %v", strategy.threads)
+	}
+	if strategy.noRand {
+		t.Fatal("Expected every context to have the generator, and the root at depth 0")
+	}
+	if strategy.maxDepth == 0 || int(strategy.maxDepth) >= tree.MaxDepth()+1 {
+		t.Fatalf("Expected the depths in (0, %d], got %d", tree.MaxDepth(), strategy.maxDepth)
+	}
+}
+
+func TestBetaSample(t *testing.T) {
+	const samples = 20000
+	r := rand.New(rand.NewSource(1))
+	for _, params := range [][2]float64{{1, 1}, {2, 8}, {30.5, 10}} {
+		mean := 0.0
+		for range samples {
+			v := betaSample(r, params[0], params[1])
+			if v < 0 || v > 1 || math.IsNaN(v) {
+				t.Fatalf("Beta%v: invalid sample %f", params, v)
+			}
+			mean += v / samples
+		}
+		if expected := params[0] / (params[0] + params[1]); math.Abs(mean-expected) > 0.01 {
+			t.Fatalf("Beta%v: expected mean %f, got %f", params, expected, mean)
+		}
+	}
+}
+
+func TestThompsonSampling(t *testing.T) {
+	strategy := NewThompsonSampling[Move, *VarianceStats, Result, *BanditOps](1)
+	tree := NewMTCS(strategy, &BanditOps{}, MultithreadTreeParallel, &VarianceStats{})
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != 0 {
+		t.Fatalf("Expected the best arm to be the most visited, got %d", best.Move)
+	}
+
+	// Outside of the search, with the shared generator
+	if child := strategy.Select(tree.Root, tree.Root); child == nil || child.Parent != tree.Root {
+		t.Fatal("Expected a root child from Select")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected a panic for a non-positive prior")
+		}
+	}()
+	strategy.SetPrior(0)
+}
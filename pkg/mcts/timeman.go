@@ -0,0 +1,96 @@
+package mcts
+
+import "math"
+
+// Clock-based time management
+//
+// Limits.Clock describes the game clock (remaining time, increment, moves to go), which the
+// TimeManager turns into 2 deadlines, on every search setup:
+//
+//   - soft: the search stops there, unless the best move keeps changing (extended up to the hard one)
+//   - hard: the search always stops there
+//
+// The main search thread periodically reports the 2 most visited root children (see Limiter.UpdateRoot),
+// so the search can also stop before the soft deadline, once the runner-up can't catch up with the best move
+// (the same rule as Limits.SmartStop, which is always on with the clock).
+
+// Game clock of a two-player game, times in milliseconds
+type Clock struct {
+	Time      [2]int // remaining time of each side
+	Increment [2]int // increment per move of each side
+	MovesToGo int    // moves until the next time control, 0 if the rest of the game must be played in the remaining time
+	Side      int    // index of the side to move (0 or 1)
+}
+
+// Whether the clock was set
+func (c Clock) IsSet() bool {
+	return c.Time[0] > 0 || c.Time[1] > 0
+}
+
+// Statistics of the root, reported by the main search thread
+type RootInfo struct {
+	Best         int    // index of the most visited root child
+	BestVisits   uint32 // visits of the most visited root child
+	SecondVisits uint32 // visits of the runner-up
+	Undecided    bool   // the best child selector needs more search (see UndecidedSelector)
+	Cycles       uint32 // search cycles when the visits were counted
+}
+
+// Turns the clock into the search deadlines
+type TimeManager struct {
+	MoveOverhead      int     // time (ms) reserved for the communication lag, on every move
+	MovesToGo         int     // expected number of moves left, when the clock doesn't say
+	HardFactor        float64 // hard deadline, as a multiple of the soft one
+	MaxUsage          float64 // hard deadline, as a fraction of the remaining time
+	InstabilityFactor float64 // soft deadline extension, per best move change
+}
+
+func NewTimeManager() *TimeManager {
+	return &TimeManager{
+		MoveOverhead:      10,
+		MovesToGo:         30,
+		HardFactor:        4,
+		MaxUsage:          0.8,
+		InstabilityFactor: 0.2,
+	}
+}
+
+// Soft and hard deadlines (in ms) for the side to move
+func (tm *TimeManager) Deadlines(clock Clock) (soft, hard int) {
+	side := clock.Side & 1
+	remaining := max(1, clock.Time[side]-tm.MoveOverhead)
+	movesToGo := clock.MovesToGo
+	if movesToGo <= 0 {
+		movesToGo = max(1, tm.MovesToGo)
+	}
+
+	soft = remaining / movesToGo
+	// Increment is available only if there is enough time left to wait for it
+	if inc := clock.Increment[side]; remaining > inc {
+		soft += inc * 3 / 4
+	}
+
+	hard = min(int(float64(soft)*tm.HardFactor), int(float64(remaining)*tm.MaxUsage))
+	hard = max(1, hard)
+	soft = max(1, min(soft, hard))
+	return soft, hard
+}
+
+// Soft deadline, extended by the number of the best move changes, never beyond the hard one
+func (tm *TimeManager) Extend(soft, hard int, changes int32) int {
+	scale := 1 + tm.InstabilityFactor*float64(changes)
+	return int(math.Min(float64(soft)*scale, float64(hard)))
+}
+
+// Number of visits the search will make in the 'remaining' ms, at the current speed ('cycles' in 'elapsed' ms)
+func visitsLeft(cycles, elapsed, remaining uint32) float64 {
+	return float64(cycles) / float64(max(1, elapsed)) * float64(remaining)
+}
+
+// Whether the runner-up can't catch up with the best child, even if it gets all of the 'left' visits
+func cannotOvertake(root RootInfo, left float64) bool {
+	if root.BestVisits == 0 {
+		return false
+	}
+	return float64(root.SecondVisits)+left < float64(root.BestVisits)
+}
@@ -0,0 +1,131 @@
+package mcts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeManagerDeadlines(t *testing.T) {
+	tm := NewTimeManager()
+
+	tests := []struct {
+		clock      Clock
+		soft, hard int
+	}{
+		// remaining/30 + 3/4 of the increment, hard deadline 4x the soft one
+		{Clock{Time: [2]int{60010, 1000}, Increment: [2]int{1000, 0}}, 2750, 11000},
+		// Other side to move, no increment
+		{Clock{Time: [2]int{60010, 3010}, Increment: [2]int{1000, 0}, Side: 1}, 100, 400},
+		// Last move before the time control, hard deadline limited by the remaining time
+		{Clock{Time: [2]int{10010, 0}, MovesToGo: 1}, 8000, 8000},
+		// Increment is ignored, if there is not enough time to wait for it
+		{Clock{Time: [2]int{310, 0}, Increment: [2]int{1000, 0}}, 10, 40},
+		// Almost no time left
+		{Clock{Time: [2]int{5, 5}}, 1, 1},
+	}
+
+	for i, test := range tests {
+		soft, hard := tm.Deadlines(test.clock)
+		if soft != test.soft || hard != test.hard {
+			t.Errorf("#%d: expected deadlines (%d, %d), got (%d, %d)", i, test.soft, test.hard, soft, hard)
+		}
+	}
+
+	if soft := tm.Extend(100, 400, 5); soft != 200 {
+		t.Errorf("Expected soft deadline extended to 200, got %d", soft)
+	}
+	if soft := tm.Extend(100, 400, 100); soft != 400 {
+		t.Errorf("Expected soft deadline limited by the hard one, got %d", soft)
+	}
+}
+
+func TestLimiterClock(t *testing.T) {
+	limiter := NewLimiter(32)
+
+	// remaining 900ms, soft deadline 30ms, hard 120ms
+	limiter.SetLimits(DefaultLimits().SetClock(Clock{Time: [2]int{910, 910}}))
+	limiter.Reset()
+	if soft, hard := limiter.Deadlines(); soft != 30 || hard != 120 {
+		t.Fatalf("Expected deadlines (30, 120), got (%d, %d)", soft, hard)
+	}
+
+	if !limiter.Ok(1, 1, 1) {
+		t.Fatal("Expected the search to continue before the soft deadline")
+	}
+	time.Sleep(35 * time.Millisecond)
+	if limiter.Ok(1, 1, 1) {
+		t.Fatal("Expected the search to stop at the soft deadline")
+	}
+	limiter.EvaluateStopReason(1, 1, 1)
+	if limiter.StopReason() != StopClockSoft {
+		t.Fatalf("Expected ClockSoft stop reason, got %s", limiter.StopReason())
+	}
+
+	// Unstable best move extends the soft deadline
+	limiter.Reset()
+	for i := range 6 {
+		limiter.UpdateRoot(RootInfo{Best: i % 2, BestVisits: 10, SecondVisits: 9})
+	}
+	if soft, _ := limiter.Deadlines(); soft != 60 {
+		t.Fatalf("Expected soft deadline extended to 60, got %d", soft)
+	}
+	time.Sleep(35 * time.Millisecond)
+	if !limiter.Ok(1, 1, 1000) {
+		t.Fatal("Expected the search to continue, while the best move is unstable")
+	}
+
+	// Never beyond the hard deadline
+	for i := range 20 {
+		limiter.UpdateRoot(RootInfo{Best: i % 2, BestVisits: 10, SecondVisits: 9})
+	}
+	time.Sleep(90 * time.Millisecond)
+	if limiter.Ok(1, 1, 1000) {
+		t.Fatal("Expected the search to stop at the hard deadline")
+	}
+	limiter.EvaluateStopReason(1, 1, 1000)
+	if limiter.StopReason()&StopClockHard == 0 {
+		t.Fatalf("Expected ClockHard stop reason, got %s", limiter.StopReason())
+	}
+}
+
+func TestLimiterClockCannotOvertake(t *testing.T) {
+	limiter := NewLimiter(32)
+	limiter.SetLimits(DefaultLimits().SetClock(Clock{Time: [2]int{60000, 60000}}))
+	limiter.Reset()
+
+	// Runner-up could still catch up
+	limiter.UpdateRoot(RootInfo{Best: 0, BestVisits: 1000, SecondVisits: 900})
+	if !limiter.Ok(1, 1, 1000) {
+		t.Fatal("Expected the search to continue")
+	}
+
+	// At ~100 cycles/ms, the gap can't be closed in ~2 seconds
+	limiter.UpdateRoot(RootInfo{Best: 0, BestVisits: 1000000, SecondVisits: 10})
+	if limiter.Ok(1, 1, 100) {
+		t.Fatal("Expected the search to stop, the best move can't be overtaken")
+	}
+	limiter.EvaluateStopReason(1, 1, 100)
+	if limiter.StopReason() != StopSmart {
+		t.Fatalf("Expected Smart stop reason, got %s", limiter.StopReason())
+	}
+}
+
+func TestSearchWithClock(t *testing.T) {
+	tree := NewDummyMCTS(MultithreadTreeParallel)
+	clock := Clock{Time: [2]int{2010, 2010}, MovesToGo: 10}
+	tree.SetLimits(DefaultLimits().SetClock(clock).SetThreads(4))
+
+	start := time.Now()
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+	elapsed := time.Since(start)
+
+	_, hard := tree.Limiter.(*Limiter).Deadlines()
+	if elapsed > time.Duration(hard+50)*time.Millisecond {
+		t.Fatalf("Search took %v, hard deadline is %dms", elapsed, hard)
+	}
+	if tree.StopReason()&(StopClockSoft|StopClockHard|StopSmart) == 0 {
+		t.Fatalf("Expected the clock to stop the search, got %s", tree.StopReason())
+	}
+	t.Logf("searched %v, stop reason %s", elapsed, tree.StopReason())
+}
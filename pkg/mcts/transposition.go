@@ -0,0 +1,233 @@
+package mcts
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Monte Carlo Graph Search (MCGS) support
+// Reference: https://arxiv.org/abs/2012.11045
+//
+// The tree itself stays a tree (every node has exactly one parent, so the backpropagation
+// can still follow the 'Parent' pointers), but nodes reaching the same position through
+// different move orders share the same statistics object. This way the visits and outcomes
+// gathered in one branch are immediately visible in every transposition of that position.
+
+const ttShardCount = 64
+
+type ttShard[S any] struct {
+	mx      sync.RWMutex
+	entries map[uint64]S
+}
+
+// Concurrent transposition table, maps position hashes to the shared node statistics
+type TranspositionTable[T MoveLike, S NodeStatsLike[S]] struct {
+	shards [ttShardCount]ttShard[S]
+	// First node created with given statistics, used to continue
+	// the principal variation through the transposed subtree
+	owners *sync.Map
+	size   atomic.Uint32
+}
+
+// Owner of the statistics and their position hash
+type ttOwner[T MoveLike, S NodeStatsLike[S]] struct {
+	node *NodeBase[T, S]
+	hash uint64
+}
+
+// Approximate size of a single table entry in bytes (key, value and the owner entry)
+const ttEntrySize = uint32(2*unsafe.Sizeof(uint64(0)) + 3*unsafe.Sizeof(uintptr(0)))
+
+func NewTranspositionTable[T MoveLike, S NodeStatsLike[S]]() *TranspositionTable[T, S] {
+	table := &TranspositionTable[T, S]{owners: &sync.Map{}}
+	for i := range table.shards {
+		table.shards[i].entries = make(map[uint64]S)
+	}
+	return table
+}
+
+func (tt *TranspositionTable[T, S]) shard(hash uint64) *ttShard[S] {
+	return &tt.shards[hash%ttShardCount]
+}
+
+// Get the statistics stored for given position hash
+func (tt *TranspositionTable[T, S]) Lookup(hash uint64) (S, bool) {
+	shard := tt.shard(hash)
+	shard.mx.RLock()
+	stats, ok := shard.entries[hash]
+	shard.mx.RUnlock()
+	return stats, ok
+}
+
+// Returns the statistics stored under given hash, if there is no such entry,
+// stores the 'node' stats and returns them (second value is then false)
+func (tt *TranspositionTable[T, S]) LookupOrStore(hash uint64, node *NodeBase[T, S]) (S, bool) {
+	shard := tt.shard(hash)
+	shard.mx.Lock()
+	defer shard.mx.Unlock()
+
+	if stats, ok := shard.entries[hash]; ok {
+		return stats, true
+	}
+
+	shard.entries[hash] = node.Stats
+	tt.owners.Store(any(node.Stats), ttOwner[T, S]{node: node, hash: hash})
+	tt.size.Add(1)
+	return node.Stats, false
+}
+
+// Returns the node that was first created with given statistics, or nil
+// if these stats are not in the table
+func (tt *TranspositionTable[T, S]) Owner(stats S) *NodeBase[T, S] {
+	if owner, ok := tt.owners.Load(any(stats)); ok {
+		return owner.(ttOwner[T, S]).node
+	}
+	return nil
+}
+
+// Number of positions stored in the table
+func (tt *TranspositionTable[T, S]) Size() uint32 {
+	return tt.size.Load()
+}
+
+// Approximation of the memory used by the table
+func (tt *TranspositionTable[T, S]) MemoryUsage() uint32 {
+	return tt.Size() * ttEntrySize
+}
+
+// Remove all entries, must not be called during the search
+func (tt *TranspositionTable[T, S]) Clear() {
+	for i := range tt.shards {
+		tt.shards[i].mx.Lock()
+		tt.shards[i].entries = make(map[uint64]S)
+		tt.shards[i].mx.Unlock()
+	}
+	tt.owners = &sync.Map{}
+	tt.size.Store(0)
+}
+
+// Removes the entries of the positions that are no longer in the tree of 'root' (after MakeMove),
+// and moves their owners to the nodes of that tree. Must not be called during the search.
+func (tt *TranspositionTable[T, S]) Retain(root *NodeBase[T, S]) {
+	// Node of every statistics in the tree, preferring the previous owner, then the expanded nodes
+	nodes := make(map[any]*NodeBase[T, S])
+	var walk func(node *NodeBase[T, S])
+	walk = func(node *NodeBase[T, S]) {
+		key := any(node.Stats)
+		owner := tt.Owner(node.Stats)
+		if prev, ok := nodes[key]; !ok || prev != owner && (node == owner || node.Expanded() && !prev.Expanded()) {
+			nodes[key] = node
+		}
+		for i := range node.Children {
+			walk(&node.Children[i])
+		}
+	}
+	walk(root)
+
+	owners := &sync.Map{}
+	size := uint32(0)
+	for i := range tt.shards {
+		shard := &tt.shards[i]
+		shard.mx.Lock()
+		for hash, stats := range shard.entries {
+			if node, ok := nodes[any(stats)]; ok {
+				owners.Store(any(stats), ttOwner[T, S]{node: node, hash: hash})
+				size++
+			} else {
+				delete(shard.entries, hash)
+			}
+		}
+		shard.mx.Unlock()
+	}
+	tt.owners = owners
+	tt.size.Store(size)
+}
+
+// Removes the entries of the positions owned by the descendants of 'node', when its subtree is freed
+// (see MCTS.prune). Nodes outside of the subtree sharing these statistics keep them, but new
+// transpositions aren't linked with them anymore. Other search threads must be paused.
+func (tt *TranspositionTable[T, S]) Evict(node *NodeBase[T, S]) {
+	for i := range node.Children {
+		child := &node.Children[i]
+		if value, ok := tt.owners.Load(any(child.Stats)); ok && value.(ttOwner[T, S]).node == child {
+			shard := tt.shard(value.(ttOwner[T, S]).hash)
+			shard.mx.Lock()
+			delete(shard.entries, value.(ttOwner[T, S]).hash)
+			shard.mx.Unlock()
+
+			tt.owners.Delete(any(child.Stats))
+			tt.size.Add(^uint32(0))
+		}
+		tt.Evict(child)
+	}
+}
+
+// Moves the owners among the 'from' nodes to their copies in 'to' (same index),
+// when a children slice is reallocated. Other search threads must not use these nodes.
+func (tt *TranspositionTable[T, S]) moveOwners(from, to []NodeBase[T, S]) {
+	for i := range from {
+		if value, ok := tt.owners.Load(any(from[i].Stats)); ok && value.(ttOwner[T, S]).node == &from[i] {
+			tt.owners.Store(any(to[i].Stats), ttOwner[T, S]{node: &to[i], hash: value.(ttOwner[T, S]).hash})
+		}
+	}
+}
+
+// Checks if any node on the path from 'node' to the root uses given stats,
+// sharing them would count the same playout twice during the backpropagation
+func sharedWithAncestor[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S], stats S) bool {
+	for ; node != nil; node = node.Parent {
+		if any(node.Stats) == any(stats) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hashes the new 'children' of the 'node' (with the position of 'ops' set to it) and links
+// them with the statistics of the already reached positions (if there are any)
+func (mcts *MCTS[T, S, R, O, A]) linkTranspositions(node *NodeBase[T, S], children []NodeBase[T, S], ops O) {
+	hashOps, ok := GameOperations[T, S, R, O](ops).(HashGameOperations[T, S, R, O])
+	if !ok {
+		return
+	}
+
+	for i := range children {
+		child := &children[i]
+		ops.Traverse(child.Move)
+		hash := hashOps.Hash()
+		ops.BackTraverse()
+
+		if stats, found := mcts.table.LookupOrStore(hash, child); found && !sharedWithAncestor(node, stats) {
+			child.Stats = stats
+		}
+	}
+}
+
+// Enables (or disables) transposition detection, GameOperations must implement
+// HashGameOperations. Has no effect in root-parallel mode, since each thread builds
+// its own tree there. Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetTranspositions(enabled bool) {
+	if !enabled {
+		mcts.table = nil
+		return
+	}
+
+	if _, ok := GameOperations[T, S, R, O](mcts.ops).(HashGameOperations[T, S, R, O]); !ok {
+		panic("[MCTS] SetTranspositions: GameOperations must implement HashGameOperations")
+	}
+
+	if mcts.table == nil {
+		mcts.table = NewTranspositionTable[T, S]()
+	}
+}
+
+// Returns the transposition table, nil if transpositions are disabled
+func (mcts *MCTS[T, S, R, O, A]) Transpositions() *TranspositionTable[T, S] {
+	return mcts.table
+}
+
+// Whether the children of expanded nodes should be linked with the transposition table
+func (mcts *MCTS[T, S, R, O, A]) useTranspositions() bool {
+	return mcts.table != nil && mcts.multithreadPolicy != MultithreadRootParallel
+}
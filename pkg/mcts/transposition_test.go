@@ -0,0 +1,228 @@
+package mcts
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Game, where the position is defined only by the set of moves played by each side,
+// so every move order reaching the same sets is a transposition
+
+const (
+	transBranchFactor = 4
+	transMaxDepth     = 6
+)
+
+var transKeys = func() [2][transBranchFactor]uint64 {
+	r := rand.New(rand.NewSource(7))
+	keys := [2][transBranchFactor]uint64{}
+	for side := range keys {
+		for m := range keys[side] {
+			keys[side][m] = r.Uint64()
+		}
+	}
+	return keys
+}()
+
+type TransOps struct {
+	history []Move
+	rand    *rand.Rand
+	// Moves made before the search (see MCTS.MakeMove), backpropagation also undoes the root
+	base int
+}
+
+func (o *TransOps) Reset()          {}
+func (o *TransOps) Traverse(m Move) { o.history = append(o.history, m) }
+func (o *TransOps) BackTraverse() {
+	if len(o.history) > o.base {
+		o.history = o.history[:len(o.history)-1]
+	}
+}
+
+func (o *TransOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if len(o.history) >= transMaxDepth {
+		return 0
+	}
+
+	terminal := len(o.history)+1 >= transMaxDepth
+	parent.Children = make([]NodeBase[Move, *NodeStats], transBranchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), terminal, &NodeStats{})
+	}
+	return transBranchFactor
+}
+
+func (o *TransOps) Rollout() Result {
+	return Result(o.rand.Intn(3)) / 2
+}
+
+func (o *TransOps) Hash() uint64 {
+	hash := uint64(len(o.history) % 2)
+	for i, m := range o.history {
+		hash += transKeys[i%2][m]
+	}
+	return hash
+}
+
+func (o *TransOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *TransOps) Clone() *TransOps {
+	return &TransOps{history: append([]Move(nil), o.history...), base: len(o.history)}
+}
+
+func newTransMCTS() *MCTS[Move, *NodeStats, Result, *TransOps, *UCB1[Move, *NodeStats, Result, *TransOps]] {
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *TransOps](0.45),
+		&TransOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetTranspositions(true)
+	return tree
+}
+
+func TestTranspositionsShareStats(t *testing.T) {
+	tree := newTransMCTS()
+	tree.SetLimits(DefaultLimits().SetCycles(20000).SetThreads(4))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if tree.Transpositions().Size() == 0 {
+		t.Fatal("No positions stored in the transposition table")
+	}
+
+	// 0 1 2 and 2 1 0 reach the same position
+	a := &tree.Root.Children[0]
+	b := &tree.Root.Children[2]
+	if !a.Expanded() || !b.Expanded() {
+		t.Fatal("Root children should be expanded after search")
+	}
+
+	// Different positions, first player played 0 or 2
+	if a.Children[1].Stats == b.Children[1].Stats {
+		t.Fatal("Different positions share the same stats")
+	}
+
+	if !a.Children[1].Expanded() || !b.Children[1].Expanded() {
+		t.Skip("Search didn't reach depth 3 in both lines")
+	}
+
+	if a.Children[1].Children[2].Stats != b.Children[1].Children[0].Stats {
+		t.Fatal("Transposed positions don't share the stats")
+	}
+
+	pv, _, _ := tree.Pv(tree.Root, BestChildMostVisits, false)
+	if len(pv) < 3 {
+		t.Fatalf("Pv too short in graph mode: %v", pv)
+	}
+}
+
+func TestTranspositionsMemoryLimit(t *testing.T) {
+	tree := newTransMCTS()
+	tree.SetLimits(DefaultLimits().SetCycles(20000))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	withTable := tree.MemoryUsage()
+	table := tree.table
+	tree.table = nil
+	withoutTable := tree.MemoryUsage()
+	tree.table = table
+
+	if withTable-withoutTable != table.MemoryUsage() || table.MemoryUsage() == 0 {
+		t.Fatalf("Table memory not included in memory usage: %d vs %d", withTable, withoutTable)
+	}
+
+	if tree.limiterSize() <= tree.Size() {
+		t.Fatalf("Table not counted towards the memory limit: %d <= %d", tree.limiterSize(), tree.Size())
+	}
+
+	// Only the root's children should remain
+	tree.Reset(false, &NodeStats{})
+	if tree.Transpositions().Size() != uint32(len(tree.Root.Children)) {
+		t.Fatal("Reset should clear the transposition table")
+	}
+}
+
+// TransOps allocating the nodes from the pool
+type PooledTransOps struct {
+	TransOps
+	pool *NodePool[Move, NodeStats, *NodeStats]
+}
+
+func (o *PooledTransOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if len(o.history) >= transMaxDepth {
+		return 0
+	}
+
+	terminal := len(o.history)+1 >= transMaxDepth
+	parent.Children = o.pool.Children(transBranchFactor)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), terminal, o.pool.Stats())
+	}
+	return transBranchFactor
+}
+
+func (o *PooledTransOps) Clone() *PooledTransOps {
+	return &PooledTransOps{TransOps: *o.TransOps.Clone(), pool: o.pool}
+}
+
+func TestTranspositionsMakeMove(t *testing.T) {
+	pool := NewNodePool[Move, NodeStats, *NodeStats](256)
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *PooledTransOps](0.45),
+		&PooledTransOps{pool: pool},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetNodePool(pool)
+	tree.SetTranspositions(true)
+
+	for ply := range 3 {
+		tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(4))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		if !tree.MakeMove(tree.BestMove()) {
+			t.Fatalf("Ply %d: MakeMove failed", ply)
+		}
+
+		// Only the positions of the new tree remain, owned by its nodes
+		distinct := map[*NodeStats]bool{}
+		var check func(node *NodeBase[Move, *NodeStats])
+		check = func(node *NodeBase[Move, *NodeStats]) {
+			// Root's children were expanded before the transpositions were enabled
+			owner := tree.Transpositions().Owner(node.Stats)
+			if owner == nil && node != tree.Root {
+				t.Fatalf("Ply %d: position of the tree isn't in the table", ply)
+			} else if owner == nil {
+				owner = node
+			} else {
+				distinct[node.Stats] = true
+			}
+			for owner.Parent != nil {
+				owner = owner.Parent
+			}
+			if owner != tree.Root {
+				t.Fatalf("Ply %d: owner outside of the tree", ply)
+			}
+			for i := range node.Children {
+				check(&node.Children[i])
+			}
+		}
+		check(tree.Root)
+
+		if size := int(tree.Transpositions().Size()); size != len(distinct) {
+			t.Fatalf("Ply %d: expected %d positions in the table, got %d", ply, len(distinct), size)
+		}
+
+		tree.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(4))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+		if pv, _, _ := tree.Pv(tree.Root, BestChildMostVisits, false); len(pv) == 0 && !tree.Root.Terminal() {
+			t.Fatalf("Ply %d: empty Pv after the move", ply)
+		}
+	}
+}
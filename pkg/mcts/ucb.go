@@ -27,10 +27,15 @@ func (u *UCB1[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S]
 	var actualVisits, visits, vl int32
 	var wins Result
 
-	for i := 0; i < len(parent.Children); i++ {
+	children := parent.VisibleChildren()
+	for i := 0; i < len(children); i++ {
 
 		// Get the variables
-		child = &parent.Children[i]
+		child = &children[i]
+		if child.ProvenLoss() {
+			// No point in choosing a losing move (see MCTS.SetSolver)
+			continue
+		}
 		visits, vl = child.Stats.GetVvl()
 		actualVisits = visits - vl
 
@@ -55,7 +60,11 @@ func (u *UCB1[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S]
 		}
 	}
 
-	return &parent.Children[index]
+	return &children[index]
+}
+
+func (b *UCB1[T, S, R, O]) BackpropagateBatch(ops O, node *NodeBase[T, S], sum Result, count int32) {
+	backpropagateSum(ops, node, sum, count)
 }
 
 func (b *UCB1[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result Result) {
@@ -83,6 +92,11 @@ func (b *UCB1[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result Res
 		// Add the outcome
 		node.Stats.AddQ(result)
 
+		// Chance node shares the perspective of its outcomes (see ChanceGameOperations)
+		if node.Parent != nil && node.Parent.Chance() {
+			result = 1.0 - result
+		}
+
 		// Backpropagate
 		node = node.Parent
 		ops.BackTraverse()
@@ -0,0 +1,227 @@
+package mcts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+)
+
+// Variance-aware selection: UCB1-Tuned and UCB-V
+// Reference: https://link.springer.com/article/10.1023/A:1013689704352 (Auer et al., Finite-time Analysis of the Multiarmed Bandit Problem)
+// Reference: https://www.sciencedirect.com/science/article/pii/S030439750900067X (Audibert et al., Exploration-exploitation tradeoff using variance estimates in multi-armed bandits)
+//
+// UCB1 explores every child at the same rate, no matter how noisy its results are. Both strategies
+// estimate the variance of the results (from the sum of squares, see VarianceStatsLike), and explore
+// less the children with consistent results. Results must be in [0, 1].
+
+type VarianceStatsLike[S any] interface {
+	NodeStatsLike[S]
+
+	// Sum of squared results
+	SumSquares() Result
+	AddSquare(Result)
+}
+
+// Node statistics with the sum of squared results
+type VarianceStats struct {
+	NodeStats
+
+	// Sum of squared results, with 10^-6 precision
+	sumSquares uint64
+}
+
+func DefaultVarianceStats() *VarianceStats {
+	return &VarianceStats{}
+}
+
+func (s *VarianceStats) Clone() *VarianceStats {
+	return &VarianceStats{
+		NodeStats:  *s.NodeStats.Clone(),
+		sumSquares: atomic.LoadUint64(&s.sumSquares),
+	}
+}
+
+func (s *VarianceStats) SumSquares() Result {
+	return Result(atomic.LoadUint64(&s.sumSquares)) / 1e6
+}
+
+func (s *VarianceStats) AddSquare(result Result) {
+	atomic.AddUint64(&s.sumSquares, uint64(result*result*1e6))
+}
+
+// Size of the VarianceStats binary encoding
+const varianceStatsBinarySize = nodeStatsBinarySize + 8
+
+// Encodes the node counters and the sum of squares, implements encoding.BinaryMarshaler
+func (s *VarianceStats) MarshalBinary() ([]byte, error) {
+	return s.appendBinary(make([]byte, 0, varianceStatsBinarySize)), nil
+}
+
+func (s *VarianceStats) appendBinary(buf []byte) []byte {
+	buf = s.NodeStats.appendBinary(buf)
+	return binary.LittleEndian.AppendUint64(buf, atomic.LoadUint64(&s.sumSquares))
+}
+
+// Decodes stats written by MarshalBinary, implements encoding.BinaryUnmarshaler
+func (s *VarianceStats) UnmarshalBinary(data []byte) error {
+	if len(data) < varianceStatsBinarySize {
+		return fmt.Errorf("[MCTS] VarianceStats: expected %d bytes, got %d", varianceStatsBinarySize, len(data))
+	}
+
+	if err := s.NodeStats.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&s.sumSquares, binary.LittleEndian.Uint64(data[nodeStatsBinarySize:]))
+	return nil
+}
+
+// Sample variance of the results, from 'visits' results (without the virtual loss)
+func resultVariance[S VarianceStatsLike[S]](stats S, visits int32) float64 {
+	mean := float64(stats.Q()) / float64(visits)
+	return max(0, float64(stats.SumSquares())/float64(visits)-mean*mean)
+}
+
+// UCB1-Tuned: mean + C * sqrt(ln(parent_visits)/visits * min(1/4, variance + sqrt(2 * ln(parent_visits)/visits)))
+type UCB1Tuned[T MoveLike, S VarianceStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	ExplorationParam float64
+}
+
+// Exploration constant 'c', 1 is the original formula
+func NewUCB1Tuned[T MoveLike, S VarianceStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](c float64) *UCB1Tuned[T, S, R, O] {
+	return &UCB1Tuned[T, S, R, O]{ExplorationParam: c}
+}
+
+func (u *UCB1Tuned[T, S, R, O]) SetExplorationParam(c float64) *UCB1Tuned[T, S, R, O] {
+	u.ExplorationParam = max(0, c)
+	return u
+}
+
+func (u *UCB1Tuned[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := math.Inf(-1)
+	index := 0
+	lnParentVisits := math.Log(float64(parent.Stats.N()))
+
+	children := parent.VisibleChildren()
+	for i := range children {
+		child := &children[i]
+		if child.ProvenLoss() {
+			// No point in choosing a losing move (see MCTS.SetSolver)
+			continue
+		}
+
+		visits, vl := child.Stats.GetVvl()
+		actualVisits := visits - vl
+		if actualVisits == 0 {
+			return child
+		}
+
+		// Variance upper bound, 1/4 is the maximum variance of a result in [0, 1]
+		bound := resultVariance(child.Stats, actualVisits) + math.Sqrt(2*lnParentVisits/float64(actualVisits))
+		ucb := float64(child.Stats.Q())/float64(visits) +
+			u.ExplorationParam*math.Sqrt(lnParentVisits/float64(visits)*min(0.25, bound))
+
+		if ucb > best {
+			best = ucb
+			index = i
+		}
+	}
+
+	return &children[index]
+}
+
+func (u *UCB1Tuned[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result Result) {
+	backpropagateSquares(ops, node, result)
+}
+
+// UCB-V: mean + sqrt(2 * variance * E / visits) + C * 3 * E / visits, where E = zeta * ln(parent_visits)
+type UCBV[T MoveLike, S VarianceStatsLike[S], R GameResult, O GameOperations[T, S, R, O]] struct {
+	// Exploration rate zeta, must be > 1 for the regret bounds to hold
+	ExplorationParam float64
+	// Weight C of the range term, the exploration of children with zero variance
+	BiasParam float64
+}
+
+// Exploration rate 'zeta' and the range term weight 'c', the paper uses zeta = 1.2, c = 1
+func NewUCBV[T MoveLike, S VarianceStatsLike[S], R GameResult, O GameOperations[T, S, R, O]](zeta, c float64) *UCBV[T, S, R, O] {
+	return &UCBV[T, S, R, O]{ExplorationParam: zeta, BiasParam: c}
+}
+
+func (u *UCBV[T, S, R, O]) SetExplorationParam(zeta float64) *UCBV[T, S, R, O] {
+	u.ExplorationParam = max(0, zeta)
+	return u
+}
+
+func (u *UCBV[T, S, R, O]) SetBiasParam(c float64) *UCBV[T, S, R, O] {
+	u.BiasParam = max(0, c)
+	return u
+}
+
+func (u *UCBV[T, S, R, O]) Select(parent, root *NodeBase[T, S]) *NodeBase[T, S] {
+	if parent.Terminal() {
+		return parent
+	}
+
+	best := math.Inf(-1)
+	index := 0
+	exploration := u.ExplorationParam * math.Log(float64(parent.Stats.N()))
+
+	children := parent.VisibleChildren()
+	for i := range children {
+		child := &children[i]
+		if child.ProvenLoss() {
+			continue
+		}
+
+		visits, vl := child.Stats.GetVvl()
+		actualVisits := visits - vl
+		if actualVisits == 0 {
+			return child
+		}
+
+		// Results are in [0, 1], so the range is 1
+		ucb := float64(child.Stats.Q())/float64(visits) +
+			math.Sqrt(2*resultVariance(child.Stats, actualVisits)*exploration/float64(visits)) +
+			u.BiasParam*3*exploration/float64(visits)
+
+		if ucb > best {
+			best = ucb
+			index = i
+		}
+	}
+
+	return &children[index]
+}
+
+func (u *UCBV[T, S, R, O]) Backpropagate(ops O, node *NodeBase[T, S], result Result) {
+	backpropagateSquares(ops, node, result)
+}
+
+// Two-player zero-sum backpropagation (see DefaultBackprop), also accumulating the squared results
+func backpropagateSquares[T MoveLike, S VarianceStatsLike[S]](ops interface{ BackTraverse() }, node *NodeBase[T, S], result Result) {
+	for node != nil {
+
+		// Reverse virtual loss for non-root
+		if node.Parent != nil {
+			node.Stats.AddVvl(1-VirtualLoss, -VirtualLoss)
+		} else {
+			node.Stats.AddVvl(1, 0)
+		}
+
+		result = 1.0 - result // switch the result
+		node.Stats.AddQ(result)
+		node.Stats.AddSquare(result)
+
+		// Chance node shares the perspective of its outcomes (see ChanceGameOperations)
+		if node.Parent != nil && node.Parent.Chance() {
+			result = 1.0 - result
+		}
+
+		node = node.Parent
+		ops.BackTraverse()
+	}
+}
@@ -0,0 +1,121 @@
+package mcts
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// One move bandit, each arm has a different reward distribution:
+// 0 - always 0.6, 1 - 0 or 1 (mean 0.5), 2 - always 0.4, 3 - 0 or 1 (mean 0.45)
+type BanditOps struct {
+	depth int
+	arm   Move
+	rand  *rand.Rand
+}
+
+func (o *BanditOps) Reset() {}
+func (o *BanditOps) Traverse(m Move) {
+	o.depth++
+	o.arm = m
+}
+
+// Called for the root as well
+func (o *BanditOps) BackTraverse() {
+	o.depth = max(0, o.depth-1)
+}
+
+func (o *BanditOps) ExpandNode(parent *NodeBase[Move, *VarianceStats]) uint32 {
+	if o.depth > 0 {
+		return 0
+	}
+
+	parent.Children = make([]NodeBase[Move, *VarianceStats], 4)
+	for i := range parent.Children {
+		parent.Children[i] = *NewBaseNode(parent, Move(i), false, &VarianceStats{})
+	}
+	return 4
+}
+
+// Result for the player to move after the arm was chosen
+func (o *BanditOps) Rollout() Result {
+	reward := 0.0
+	switch o.arm {
+	case 0:
+		reward = 0.6
+	case 1:
+		if o.rand.Float64() < 0.5 {
+			reward = 1
+		}
+	case 2:
+		reward = 0.4
+	case 3:
+		if o.rand.Float64() < 0.45 {
+			reward = 1
+		}
+	}
+	return Result(1 - reward)
+}
+
+func (o *BanditOps) SetRand(r *rand.Rand) {
+	o.rand = r
+}
+
+func (o *BanditOps) Clone() *BanditOps {
+	return &BanditOps{depth: o.depth, arm: o.arm}
+}
+
+func TestVarianceStats(t *testing.T) {
+	stats := &VarianceStats{}
+	for _, result := range []Result{0, 1, 0.5, 0.5} {
+		stats.AddVvl(1, 0)
+		stats.AddQ(result)
+		stats.AddSquare(result)
+	}
+
+	if math.Abs(float64(stats.SumSquares())-1.5) > 1e-6 {
+		t.Fatalf("Expected sum of squares 1.5, got %f", stats.SumSquares())
+	}
+	if variance := resultVariance(stats, stats.RealVisits()); math.Abs(variance-0.125) > 1e-6 {
+		t.Fatalf("Expected variance 0.125, got %f", variance)
+	}
+
+	data, err := stats.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &VarianceStats{}
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SumSquares() != stats.SumSquares() || decoded.N() != stats.N() || decoded.Q() != stats.Q() {
+		t.Fatalf("Decoded stats differ: %+v, expected %+v", decoded, stats)
+	}
+	if err := decoded.UnmarshalBinary(data[:nodeStatsBinarySize]); err == nil {
+		t.Fatal("Expected an error for truncated data")
+	}
+}
+
+func TestVarianceStrategies(t *testing.T) {
+	strategies := map[string]StrategyLike[Move, *VarianceStats, Result, *BanditOps]{
+		"UCB1-Tuned": NewUCB1Tuned[Move, *VarianceStats, Result, *BanditOps](1),
+		"UCB-V":      NewUCBV[Move, *VarianceStats, Result, *BanditOps](1.2, 1),
+	}
+
+	for name, strategy := range strategies {
+		tree := NewMTCS(strategy, &BanditOps{}, MultithreadTreeParallel, &VarianceStats{})
+		tree.SetLimits(DefaultLimits().SetCycles(5000))
+		tree.SearchMultiThreaded()
+		tree.Synchronize()
+
+		if best := tree.BestChild(tree.Root, BestChildMostVisits); best.Move != 0 {
+			t.Fatalf("%s: expected the best arm to be the most visited, got %d", name, best.Move)
+		}
+
+		// Deterministic arm, every squared result is the same
+		child := &tree.Root.Children[2]
+		if n, sq := float64(child.Stats.RealVisits()), float64(child.Stats.SumSquares()); math.Abs(sq-n*0.16) > 1e-3*n {
+			t.Fatalf("%s: expected sum of squares %f, got %f", name, n*0.16, sq)
+		}
+	}
+}
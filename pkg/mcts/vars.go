@@ -5,6 +5,9 @@ import "time"
 // Main thread id, which has some privileges, like calling the listener during the search
 const mainThreadId = 0
 
+// Number of the main thread's iterations between the root statistics updates (see LimiterLike.UpdateRoot)
+const rootUpdateInterval = 64
+
 // Virtual loss value, used in multithreaded MCTS[T, S, R, O, A]to avoid multiple threads
 // exploring the same node simultaneously
 const VirtualLoss int32 = 2
@@ -31,6 +34,11 @@ const (
 	// only on the main thread, so the evaluation and pv will be inaccurate until
 	// the results are merged after the search is done.
 	MultithreadRootParallel
+
+	// Only the main thread builds the tree, but every selected leaf is simulated
+	// Limits.NThreads times in parallel (on cloned GameOperations), and all of the results
+	// are backpropagated at once. No collisions, best suited for games with expensive rollouts.
+	MultithreadLeafParallel
 )
 
 const (
@@ -40,4 +48,16 @@ const (
 
 	// Experimental: choose the child with the best win rate
 	BestChildWinRate
+
+	// Choose the child with the best result seen (single-player games, see SinglePlayerStatsLike),
+	// with the SPMCTS strategy Pv returns the best complete sequence found
+	BestChildMaxScore
+
+	// Sample the child proportionally to visits^(1/temperature), for varied self-play games
+	// (see MCTS.SetTemperature), Pv lines are sampled as well
+	BestChildSampled
+
+	// Use the selector set with MCTS.SetBestChildSelector (the most visited child by default),
+	// used by BestMove, RootScore and the listener
+	BestChildDefault
 )
@@ -0,0 +1,184 @@
+package mcts
+
+import (
+	"math"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Progressive widening, for huge or continuous action spaces
+// Reference: https://hal.science/hal-00542673/document (Couetoux et al., Continuous Upper Confidence Trees)
+//
+// Instead of listing every action at once, ExpandNode creates only the first actions,
+// and the node may have at most ceil(K * N^Alpha) children, where N is the number of its visits.
+// When the node is visited often enough, the search asks WidenNode for the next action.
+// The Children slice gets its capacity (MaxChildren) reserved at the expansion, so that
+// adding a child never moves the existing ones, while other threads are searching them.
+// The new child is written past the visible children, and then published by an atomic
+// counter (see NodeBase.VisibleChildren), Children itself is updated after the search.
+// Like after the expansion, the new children get their players, priors (normalized again together
+// with the visible ones, see PriorGameOperations) and are linked with their transpositions.
+
+// Game operations adding the actions one by one, required by the progressive widening
+type WideningGameOperations[T MoveLike, S NodeStatsLike[S], R GameResult, O any] interface {
+	GameOperations[T, S, R, O]
+	// Appends the next action to parent.Children (with the position set to the parent),
+	// returns false if there are no more actions. Must not grow the slice beyond its capacity.
+	WidenNode(parent *NodeBase[T, S]) bool
+}
+
+type wideningParams struct {
+	k           float64
+	alpha       float64
+	maxChildren int
+}
+
+// Enables the progressive widening, node with N visits may have at most ceil(k * N^alpha) children
+// (and never more than 'maxChildren'), k <= 0 disables it. GameOperations must implement WideningGameOperations.
+// Every expanded node reserves memory for 'maxChildren' children. Must not be called during the search.
+func (mcts *MCTS[T, S, R, O, A]) SetProgressiveWidening(k, alpha float64, maxChildren int) {
+	if k <= 0 {
+		mcts.widening = nil
+		return
+	}
+
+	if _, ok := GameOperations[T, S, R, O](mcts.ops).(WideningGameOperations[T, S, R, O]); !ok {
+		panic("[MCTS] SetProgressiveWidening: GameOperations must implement WideningGameOperations")
+	}
+
+	mcts.widening = &wideningParams{
+		k:           k,
+		alpha:       min(1, max(0, alpha)),
+		maxChildren: max(1, maxChildren),
+	}
+
+	// Root was already expanded, make room for the new children
+	if mcts.Root.Expanded() {
+		mcts.reserveChildren(mcts.Root, mcts.widening.maxChildren)
+	}
+}
+
+// Returns the widening parameters, k == 0 if it's disabled
+func (mcts *MCTS[T, S, R, O, A]) ProgressiveWidening() (k, alpha float64, maxChildren int) {
+	if mcts.widening == nil {
+		return 0, 0, 0
+	}
+	return mcts.widening.k, mcts.widening.alpha, mcts.widening.maxChildren
+}
+
+// Maximum number of children of the node with given visits
+func (w *wideningParams) width(visits int32) int {
+	return min(w.maxChildren, max(1, int(math.Ceil(w.k*math.Pow(float64(visits), w.alpha)))))
+}
+
+// Whether no more children can be added to the node, only then its children's proofs can prove a win or a draw
+func (mcts *MCTS[T, S, R, O, A]) childrenComplete(node *NodeBase[T, S]) bool {
+	return mcts.widening == nil || node.Chance() || atomic.LoadUint32(&node.Flags)&ExhaustedMask != 0
+}
+
+// Grows the capacity of node's Children, so that new children can be appended in place.
+// Must be called before the children are published to other threads. The moved children
+// keep their transposition table entries, and the old slice is returned to the node pool.
+func (mcts *MCTS[T, S, R, O, A]) reserveChildren(node *NodeBase[T, S], capacity int) {
+	defer atomic.StoreUint32(&node.width, uint32(len(node.Children)))
+	if cap(node.Children) >= capacity {
+		return
+	}
+
+	var children []NodeBase[T, S]
+	if pool, ok := mcts.pool.(interface{ Children(int) []NodeBase[T, S] }); ok {
+		children = pool.Children(capacity)[:len(node.Children)]
+	} else {
+		children = make([]NodeBase[T, S], len(node.Children), capacity)
+	}
+
+	copy(children, node.Children)
+	for i := range children {
+		// Grandchildren must point to the moved children
+		for j := range children[i].Children {
+			children[i].Children[j].Parent = &children[i]
+		}
+	}
+
+	if mcts.table != nil {
+		mcts.table.moveOwners(node.Children, children)
+	}
+
+	old := node.Children
+	node.Children = children
+	if mcts.usePool() {
+		// Subtrees and stats belong to the moved children now, only the slice is released
+		clear(old)
+		mcts.pool.Release(&NodeBase[T, S]{Children: old})
+	}
+}
+
+// Adds the next child to the expanded 'node' (with the position of 'ops' set to it),
+// if the node was visited often enough. Other threads keep searching the visible children.
+func (mcts *MCTS[T, S, R, O, A]) widen(node *NodeBase[T, S], ops O) {
+	children := node.VisibleChildren()
+	n := len(children)
+	if node.Chance() || n >= cap(children) || n >= mcts.widening.width(node.Stats.RealVisits()) {
+		return
+	}
+
+	if !mcts.Limiter.Expand() || !node.startWidening() {
+		return
+	}
+
+	// Other thread might have added a child in the meantime. WidenNode appends to a copy
+	// of the node, so that the readers never see Children being modified.
+	children = node.VisibleChildren()
+	n = len(children)
+	scratch := &NodeBase[T, S]{
+		Stats:    node.Stats,
+		Move:     node.Move,
+		Children: children,
+		Parent:   node.Parent,
+		Flags:    atomic.LoadUint32(&node.Flags),
+		Player:   node.Player,
+		proof:    atomic.LoadUint32(&node.proof),
+		Prob:     node.Prob,
+	}
+
+	more := GameOperations[T, S, R, O](ops).(WideningGameOperations[T, S, R, O]).WidenNode(scratch)
+	if cap(scratch.Children) != cap(children) || unsafe.SliceData(scratch.Children) != unsafe.SliceData(children) {
+		panic("[MCTS] widen: WidenNode must not grow the Children slice beyond its capacity")
+	}
+
+	added := scratch.Children[n:]
+	if len(added) > 0 {
+		for i := range added {
+			added[i].Parent = node
+		}
+		// Same as after the expansion, the priors of the visible children are normalized again
+		mcts.setPlayers(node, added, ops)
+		mcts.setPriors(scratch, len(added), ops)
+		if mcts.useTranspositions() {
+			mcts.linkTranspositions(node, added, ops)
+		}
+		mcts.size.Add(uint32(len(added)))
+		atomic.StoreUint32(&node.width, uint32(len(scratch.Children)))
+	}
+
+	if !more || len(added) == 0 || len(scratch.Children) == cap(children) {
+		node.updateFlags(ExhaustedMask, ExpandingMask)
+		// Proofs of the children are final now
+		if mcts.solver && len(added) == 0 {
+			mcts.proveAncestors(node)
+		}
+	} else {
+		node.updateFlags(0, ExpandingMask)
+	}
+}
+
+// Updates Children of the widened nodes in the 'node' subtree to the visible children,
+// must not be called during the search
+func commitChildren[T MoveLike, S NodeStatsLike[S]](node *NodeBase[T, S]) {
+	node.Children = node.VisibleChildren()
+	for i := range node.Children {
+		if len(node.Children[i].Children) > 0 {
+			commitChildren(&node.Children[i])
+		}
+	}
+}
@@ -0,0 +1,356 @@
+package mcts
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+)
+
+// Single decision with continuous action x in [0, 1), the reward is 1 - |x - widenTarget|.
+// Actions are generated one by one, using the golden ratio sequence.
+const widenTarget = 0.73
+
+type WideningOps struct {
+	depth int
+}
+
+func widenAction(m Move) float64 {
+	_, frac := math.Modf(float64(m) * 0.6180339887)
+	return frac
+}
+
+func (o *WideningOps) Reset()        {}
+func (o *WideningOps) Traverse(Move) { o.depth++ }
+func (o *WideningOps) BackTraverse() { o.depth-- }
+
+// Only the first action, the rest comes from WidenNode
+func (o *WideningOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if o.depth > 0 {
+		return 0
+	}
+	parent.Children = append(parent.Children, *NewBaseNode(parent, Move(0), true, &NodeStats{}))
+	return 1
+}
+
+func (o *WideningOps) WidenNode(parent *NodeBase[Move, *NodeStats]) bool {
+	move := Move(len(parent.Children))
+	parent.Children = append(parent.Children, *NewBaseNode(parent, move, true, &NodeStats{}))
+	return true
+}
+
+// Not used, the leaves are scored by widenEvaluator
+func (o *WideningOps) Rollout() Result {
+	return 0.5
+}
+
+func (o *WideningOps) Clone() *WideningOps {
+	return &WideningOps{depth: o.depth}
+}
+
+// Score of the action, from the enemy's perspective
+func widenEvaluator(ops *WideningOps, leaf *NodeBase[Move, *NodeStats]) Result {
+	return Result(math.Abs(widenAction(leaf.Move) - widenTarget))
+}
+
+func TestProgressiveWidening(t *testing.T) {
+	const maxChildren = 64
+
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *WideningOps](0.2),
+		&WideningOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetEvaluator(EvaluatorFunc[Move, *NodeStats, Result, *WideningOps](widenEvaluator))
+	tree.SetProgressiveWidening(1, 0.5, maxChildren)
+
+	if k, alpha, max := tree.ProgressiveWidening(); k != 1 || alpha != 0.5 || max != maxChildren {
+		t.Fatalf("Unexpected widening parameters: %f %f %d", k, alpha, max)
+	}
+
+	if len(tree.Root.Children) != 1 || cap(tree.Root.Children) != maxChildren {
+		t.Fatalf("Expected 1 child with reserved capacity, got %d/%d", len(tree.Root.Children), cap(tree.Root.Children))
+	}
+
+	tree.SetLimits(DefaultLimits().SetCycles(400).SetThreads(4))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// ceil(sqrt(400)) = 20 children at most
+	if n := len(tree.Root.Children); n < 10 || n > 20 {
+		t.Fatalf("Expected the root to widen up to 20 children, got %d", n)
+	}
+
+	tree.SetLimits(DefaultLimits().SetCycles(20000).SetThreads(4))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	if n := len(tree.Root.Children); n != maxChildren || tree.Root.Flags&ExhaustedMask == 0 {
+		t.Fatalf("Expected the root to be exhausted with %d children, got %d", maxChildren, n)
+	}
+
+	for i := range tree.Root.Children {
+		if tree.Root.Children[i].Parent != tree.Root || tree.Root.Children[i].Move != Move(i) {
+			t.Fatal("Widened children are not linked properly")
+		}
+	}
+
+	best := tree.BestChild(tree.Root, BestChildMostVisits)
+	if x := widenAction(best.Move); math.Abs(x-widenTarget) > 0.02 {
+		t.Fatalf("Expected the best action near %.2f, got %.3f", widenTarget, x)
+	}
+}
+
+func TestProgressiveWideningRequiresOps(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected panic, when ops don't implement WideningGameOperations")
+		}
+	}()
+
+	GetDummyMCTS().SetProgressiveWidening(1, 0.5, 10)
+}
+
+func TestProgressiveWideningConcurrentReaders(t *testing.T) {
+	const maxChildren = 64
+
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *WideningOps](0.2),
+		&WideningOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetEvaluator(EvaluatorFunc[Move, *NodeStats, Result, *WideningOps](widenEvaluator))
+	tree.SetProgressiveWidening(1, 0.5, maxChildren)
+
+	// Listener reads the children on every cycle, while other threads are widening the root
+	lines := 0
+	listener := NewStatsListener[Move]()
+	listener.OnCycle(func(stats ListenerTreeStats[Move]) {
+		lines += len(stats.Lines)
+	}).SetCycleInterval(1)
+	tree.SetListener(listener)
+
+	tree.SetLimits(DefaultLimits().SetCycles(2000).SetThreads(8).SetMultiPv(4))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	t.Logf("listener saw %d lines", lines)
+
+	// Widened children are moved to Children after the search
+	children := tree.Root.Children
+	if len(tree.Root.VisibleChildren()) != len(children) || len(children) < 20 {
+		t.Fatalf("Expected the root to widen, got %d (%d visible) children", len(children), len(tree.Root.VisibleChildren()))
+	}
+	for i := range children {
+		if children[i].Parent != tree.Root || children[i].Move != Move(i) {
+			t.Fatalf("Child %d isn't linked to the root", i)
+		}
+	}
+}
+
+// Widening ops with move priors and position hashes, the actions divisible by 3 reach the same position
+type PriorWideningOps struct {
+	depth int
+	last  Move
+}
+
+func (o *PriorWideningOps) Reset() {}
+func (o *PriorWideningOps) Traverse(move Move) {
+	o.depth++
+	o.last = move
+}
+func (o *PriorWideningOps) BackTraverse() { o.depth = max(0, o.depth-1) }
+func (o *PriorWideningOps) Rollout() Result {
+	return 0.5
+}
+
+func (o *PriorWideningOps) ExpandNode(parent *NodeBase[Move, *PuctStats]) uint32 {
+	if o.depth > 0 {
+		return 0
+	}
+	parent.Children = append(parent.Children, *NewBaseNode(parent, Move(0), true, &PuctStats{}))
+	return 1
+}
+
+func (o *PriorWideningOps) WidenNode(parent *NodeBase[Move, *PuctStats]) bool {
+	move := Move(len(parent.Children))
+	parent.Children = append(parent.Children, *NewBaseNode(parent, move, true, &PuctStats{}))
+	return true
+}
+
+// Weight of the action is its index + 1
+func (o *PriorWideningOps) Priors(parent *NodeBase[Move, *PuctStats], priors []float64) {
+	for i := range priors {
+		priors[i] = float64(parent.Children[i].Move + 1)
+	}
+}
+
+func (o *PriorWideningOps) Hash() uint64 {
+	if o.depth == 0 {
+		return 0
+	}
+	if o.last%3 == 0 {
+		return 1
+	}
+	return 2 + uint64(o.last)
+}
+
+func (o *PriorWideningOps) Clone() *PriorWideningOps {
+	return &PriorWideningOps{depth: o.depth, last: o.last}
+}
+
+func TestProgressiveWideningPriorsAndTranspositions(t *testing.T) {
+	tree := NewMTCS(
+		NewPUCT[Move, *PuctStats, Result, *PriorWideningOps](1),
+		&PriorWideningOps{},
+		MultithreadTreeParallel,
+		&PuctStats{},
+	)
+	tree.SetEvaluator(EvaluatorFunc[Move, *PuctStats, Result, *PriorWideningOps](
+		func(ops *PriorWideningOps, leaf *NodeBase[Move, *PuctStats]) Result {
+			return 0.5
+		}))
+	tree.SetProgressiveWidening(1, 0.5, 16)
+	tree.SetTranspositions(true)
+
+	tree.SetLimits(DefaultLimits().SetCycles(200).SetThreads(1))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	children := tree.Root.Children
+	if len(children) < 7 {
+		t.Fatalf("Expected the root to widen, got %d children", len(children))
+	}
+
+	// Priors come from PriorGameOperations, normalized again after each widening
+	// (the shared statistics hold the prior of the last linked child)
+	total := float64(len(children) * (len(children) + 1) / 2)
+	for i := range children {
+		if i%3 == 0 && i > 0 {
+			continue
+		}
+		if p := children[i].Stats.Prior(); math.Abs(p-float64(i+1)/total) > 1e-9 {
+			t.Fatalf("Child %d: expected the prior %f, got %f", i, float64(i+1)/total, p)
+		}
+	}
+
+	// Widened children reaching the same position share the statistics (the first child
+	// was expanded before the transpositions were enabled)
+	if children[6].Stats != children[3].Stats || children[4].Stats == children[3].Stats {
+		t.Fatal("Expected the widened children 3 and 6 (only) to share the statistics")
+	}
+	if size := tree.Transpositions().Size(); int(size) != len(children)-1-(len(children)-1)/3+1 {
+		t.Fatalf("Expected one table entry per widened position, got %d", size)
+	}
+}
+
+func TestProgressiveWideningExpandedRoot(t *testing.T) {
+	// Root expanded with the transpositions on, its child owns the table entry
+	tree := NewMTCS(
+		NewPUCT[Move, *PuctStats, Result, *PriorWideningOps](1),
+		&PriorWideningOps{},
+		MultithreadTreeParallel,
+		&PuctStats{},
+	)
+	tree.SetTranspositions(true)
+	tree.Reset(false, &PuctStats{})
+
+	tree.SetProgressiveWidening(1, 0.5, 16)
+	child := &tree.Root.Children[0]
+	if cap(tree.Root.Children) != 16 || tree.Transpositions().Owner(child.Stats) != child {
+		t.Fatal("Expected the moved child to own its transposition table entry")
+	}
+
+	// Without the transpositions, the old slice goes back to the pool, and the new one comes from it
+	pool := NewNodePool[Move, NodeStats, *NodeStats](64)
+	wide := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *WideningOps](0.2),
+		&WideningOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	wide.SetNodePool(pool)
+	old := wide.Root.Children
+
+	wide.SetProgressiveWidening(1, 0.5, 16)
+	if free := pool.freeNodes[cap(old)]; len(free) != 1 || unsafe.SliceData(free[0]) != unsafe.SliceData(old) {
+		t.Fatal("Expected the old children slice to be released to the pool")
+	}
+	if cap(wide.Root.Children) != 16 || pool.MemoryUsage() == 0 || wide.Root.Children[0].Move != 0 {
+		t.Fatal("Expected the reserved children to be allocated from the pool")
+	}
+}
+
+// Every action loses for the side to move, except the last one, which draws
+const solverWidenActions = 5
+
+type SolverWideningOps struct {
+	depth int
+	last  Move
+}
+
+func (o *SolverWideningOps) Reset() {}
+func (o *SolverWideningOps) Traverse(move Move) {
+	o.depth++
+	o.last = move
+}
+func (o *SolverWideningOps) BackTraverse() { o.depth = max(0, o.depth-1) }
+
+func (o *SolverWideningOps) ExpandNode(parent *NodeBase[Move, *NodeStats]) uint32 {
+	if o.depth > 0 {
+		return 0
+	}
+	parent.Children = append(parent.Children, *NewBaseNode(parent, Move(0), true, &NodeStats{}))
+	return 1
+}
+
+func (o *SolverWideningOps) WidenNode(parent *NodeBase[Move, *NodeStats]) bool {
+	if len(parent.Children) >= solverWidenActions {
+		return false
+	}
+	move := Move(len(parent.Children))
+	parent.Children = append(parent.Children, *NewBaseNode(parent, move, true, &NodeStats{}))
+	return true
+}
+
+// From the perspective of the enemy, who wins after every action but the last one
+func (o *SolverWideningOps) Rollout() Result {
+	if o.last == solverWidenActions-1 {
+		return 0.5
+	}
+	return 1
+}
+
+func (o *SolverWideningOps) Clone() *SolverWideningOps {
+	return &SolverWideningOps{depth: o.depth, last: o.last}
+}
+
+func TestProgressiveWideningSolver(t *testing.T) {
+	tree := NewMTCS(
+		NewUCB1[Move, *NodeStats, Result, *SolverWideningOps](0.4),
+		&SolverWideningOps{},
+		MultithreadTreeParallel,
+		&NodeStats{},
+	)
+	tree.SetSolver(true)
+	tree.SetProgressiveWidening(1, 0.5, 8)
+
+	tree.SetLimits(DefaultLimits().SetCycles(5000).SetThreads(2))
+	tree.SearchMultiThreaded()
+	tree.Synchronize()
+
+	// Losing actions seen first must not prove the root, before the drawing one is added
+	if n := len(tree.Root.Children); n != solverWidenActions {
+		t.Fatalf("Expected every action to be added, got %d", n)
+	}
+	if state := tree.Root.ProofState(); state != ProofDraw {
+		t.Fatalf("Expected the root to be proven a draw, got %v", state)
+	}
+	if tree.StopReason() != StopSolved {
+		t.Fatalf("Expected the search to stop once solved, got %s", tree.StopReason())
+	}
+	if best := tree.BestMove(); best != solverWidenActions-1 {
+		t.Fatalf("Expected the drawing action %d, got %d", solverWidenActions-1, best)
+	}
+}